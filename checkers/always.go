@@ -1,14 +1,45 @@
 package checkers
 
 import (
+	"context"
+
 	"github.com/ppartarr/tipsy/correctors"
 )
 
+// Always is the checker that always corrects the typos of the submitted password
+type Always struct {
+	service *Service
+}
+
+// NewAlways initialises the always checker
+func NewAlways(service *Service) *Always {
+	return &Always{service: service}
+}
+
+// Verify checks the submitted password and the passwords in the ball
+func (checker *Always) Verify(ctx context.Context, submittedPassword string, credential *Credential) (*Result, error) {
+	return checker.service.verifyCorrected(ctx, submittedPassword, credential, func(service *Service) ([]correctors.Correction, error) {
+		return service.always(submittedPassword)
+	})
+}
+
+// WithOptions returns a copy of the checker that corrects typos using the given options e.g. the keyboard layout
+func (checker *Always) WithOptions(options *correctors.Options) Checker {
+	return NewAlways(checker.service.WithOptions(options))
+}
+
 // CheckAlways checks the password and the passwords in the ball by using the given correctors
-func (checker *Checker) CheckAlways(submittedPassword string) []string {
+func (checker *Service) CheckAlways(submittedPassword string) ([]string, error) {
+	corrections, err := checker.always(submittedPassword)
+	if err != nil {
+		return nil, err
+	}
 
-	// get the ball
-	ball := correctors.GetBall(submittedPassword, checker.Correctors)
+	return correctors.Passwords(corrections), nil
+}
+
+func (checker *Service) always(submittedPassword string) ([]correctors.Correction, error) {
 
-	return ball
+	// get the ball
+	return correctors.GetCorrections(submittedPassword, checker.Correctors, checker.Options)
 }
@@ -1,10 +1,13 @@
 package checkers
 
 import (
+	"context"
 	"testing"
 
+	"github.com/ppartarr/tipsy/checkers/hashers"
 	"github.com/ppartarr/tipsy/correctors"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var testTypos = map[string]int{
@@ -28,9 +31,91 @@ var testTypos = map[string]int{
 var topCorrectors = []string{correctors.SwitchAll, correctors.RemoveLast, correctors.SwitchFirst}
 
 func TestCheckAlways(t *testing.T) {
-	checker := NewChecker(testTypos, topCorrectors)
-	ball := checker.CheckAlways("test")
+	checker := NewService(testTypos, topCorrectors)
+	ball, err := checker.CheckAlways("test")
+	assert.Nil(t, err)
 	if !assert.ElementsMatch(t, ball, []string{"Test", "TEST", "tes"}) {
 		t.Error("ball should be the set of strings containing the output of the correctors applied to the password")
 	}
 }
+
+func TestCheckAlwaysKeypressEdit(t *testing.T) {
+	checker := NewService(testTypos, []string{correctors.KeypressEdit})
+	ball, err := checker.CheckAlways("passsword")
+	assert.Nil(t, err)
+	if !assert.Contains(t, ball, "password") {
+		t.Error("ball should contain the single keypress edits of the password")
+	}
+}
+
+func TestCheckAlwaysInvalidPassword(t *testing.T) {
+	checker := NewService(testTypos, topCorrectors)
+
+	_, err := checker.CheckAlways("")
+	assert.Equal(t, correctors.ErrEmptyPassword, err)
+
+	_, err = checker.CheckAlways("password\xff")
+	assert.Equal(t, correctors.ErrInvalidPassword, err)
+}
+
+func TestAlwaysVerify(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	credential := &Credential{PasswordHash: string(hash)}
+
+	checker := NewAlways(NewService(testTypos, topCorrectors))
+
+	result, err := checker.Verify(context.Background(), "password", credential)
+	assert.Nil(t, err)
+	assert.Equal(t, &Result{Match: true, Kind: ExactMatch, BallSize: 3, Password: "password"}, result)
+
+	result, err = checker.Verify(context.Background(), "PASSWORD", credential)
+	assert.Nil(t, err)
+	assert.True(t, result.Match)
+	assert.Equal(t, CorrectedMatch, result.Kind)
+	assert.Equal(t, correctors.SwitchAll, result.Corrector)
+
+	result, err = checker.Verify(context.Background(), "drowssap", credential)
+	assert.Nil(t, err)
+	assert.False(t, result.Match)
+	assert.Equal(t, NoMatch, result.Kind)
+	assert.Equal(t, 3, result.BallSize)
+
+	_, err = checker.Verify(context.Background(), "password", &Credential{})
+	assert.NotNil(t, err)
+}
+
+func TestAlwaysVerifyConstantWork(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	credential := &Credential{PasswordHash: string(hash)}
+
+	checker := NewAlways(NewService(testTypos, topCorrectors).WithConstantWork(20))
+
+	result, err := checker.Verify(context.Background(), "password", credential)
+	assert.Nil(t, err)
+	assert.Equal(t, ExactMatch, result.Kind, "the submitted password should match first even if the ball is checked")
+
+	result, err = checker.Verify(context.Background(), "PASSWORD", credential)
+	assert.Nil(t, err)
+	assert.Equal(t, CorrectedMatch, result.Kind)
+	assert.Equal(t, 3, result.BallSize, "the dummy checks are not part of the ball")
+
+	result, err = checker.Verify(context.Background(), "drowssap", credential)
+	assert.Nil(t, err)
+	assert.False(t, result.Match)
+}
+
+func TestAlwaysVerifyHashers(t *testing.T) {
+	hash, err := hashers.NewArgon2id(1024, 1, 1, 32).Hash("password")
+	assert.Nil(t, err)
+
+	checker := NewAlways(NewService(testTypos, topCorrectors))
+	result, err := checker.Verify(context.Background(), "PASSWORD", &Credential{PasswordHash: hash})
+	assert.Nil(t, err)
+	assert.Equal(t, CorrectedMatch, result.Kind, "the ball should be verified against any supported hash")
+}
+
+func TestNewChecker(t *testing.T) {
+	assert.Equal(t, NewService(testTypos, topCorrectors), NewChecker(testTypos, topCorrectors))
+}
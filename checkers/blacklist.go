@@ -1,36 +1,221 @@
 package checkers
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"io/ioutil"
 	"log"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/ppartarr/tipsy/config"
 	"github.com/ppartarr/tipsy/correctors"
 )
 
+// Blacklist is the checker that only corrects typos to passwords that aren't in a blacklist of high-probability passwords
+type Blacklist struct {
+	service   *Service
+	blacklist PasswordSet
+}
+
+// NewBlacklist initialises the blacklist checker with a blacklist loaded in memory e.g. with LoadBlacklistFile
+func NewBlacklist(service *Service, blacklist PasswordSet) *Blacklist {
+	return &Blacklist{
+		service:   service,
+		blacklist: blacklist,
+	}
+}
+
+// Verify checks the submitted password and the passwords in the ball that aren't in the blacklist
+func (checker *Blacklist) Verify(ctx context.Context, submittedPassword string, credential *Credential) (*Result, error) {
+	return checker.service.verifyCorrected(ctx, submittedPassword, credential, func(service *Service) ([]correctors.Correction, error) {
+		return service.blacklist(submittedPassword, checker.blacklist)
+	})
+}
+
+// WithOptions returns a copy of the checker that corrects typos using the given options e.g. the keyboard layout
+func (checker *Blacklist) WithOptions(options *correctors.Options) Checker {
+	return NewBlacklist(checker.service.WithOptions(options), checker.blacklist)
+}
+
 // CheckBlacklist uses a blacklist of high-probability passwords. It checks the password or any password in the ball only if it isn't in the blacklist
-func (checker *Checker) CheckBlacklist(submittedPassword string, blacklist []string) []string {
+func (checker *Service) CheckBlacklist(submittedPassword string, blacklist []string) ([]string, error) {
+	corrections, err := checker.blacklist(submittedPassword, NewStringSet(blacklist))
+	if err != nil {
+		return nil, err
+	}
+
+	return correctors.Passwords(corrections), nil
+}
+
+func (checker *Service) blacklist(submittedPassword string, blacklist PasswordSet) ([]correctors.Correction, error) {
 
 	// get the ball
-	ball := correctors.GetBall(submittedPassword, checker.Correctors)
+	corrections, err := correctors.GetCorrections(submittedPassword, checker.Correctors, checker.Options)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, password := range ball {
+	return filterBlacklist(corrections, blacklist), nil
+}
+
+// filterBlacklist returns the corrections of the ball that aren't in the blacklist
+func filterBlacklist(corrections []correctors.Correction, blacklist PasswordSet) []correctors.Correction {
+	ball := make([]correctors.Correction, 0, len(corrections))
+	for _, correction := range corrections {
 		// check password in the ball only if it isn't in the blacklist
-		if correctors.StringInSlice(password, blacklist) {
-			ball = remove(ball, password)
+		if !blacklist.Contains(correction.Password) {
+			ball = append(ball, correction)
 		}
 	}
 
 	return ball
 }
 
-func remove(slice []string, s string) []string {
-	for i := range slice {
-		if slice[i] == s {
-			return append(slice[:i], slice[i+1:]...)
+// PasswordSet is a set of passwords with constant time lookup e.g. a blacklist
+type PasswordSet interface {
+	Contains(password string) bool
+}
+
+// StringSet is a hash set of passwords
+type StringSet map[string]struct{}
+
+// NewStringSet returns the hash set of the given passwords
+func NewStringSet(passwords []string) StringSet {
+	set := make(StringSet, len(passwords))
+	for _, password := range passwords {
+		set[password] = struct{}{}
+	}
+	return set
+}
+
+// Contains returns true if the password is in the set
+func (set StringSet) Contains(password string) bool {
+	_, ok := set[password]
+	return ok
+}
+
+// BlacklistFile is a blacklist loaded once from a file into a hash set or a bloom filter, it's reloaded when the
+// file changes. It's safe to use from several goroutines
+type BlacklistFile struct {
+	config  *config.BlacklistChecker
+	mutex   sync.RWMutex
+	set     PasswordSet
+	modTime time.Time
+	size    int64
+}
+
+// LoadBlacklistFile loads the blacklist file of the config into the filter of the config e.g. set or bloom
+func LoadBlacklistFile(blacklistConfig *config.BlacklistChecker) (*BlacklistFile, error) {
+	blacklist := &BlacklistFile{config: blacklistConfig}
+
+	_, err := blacklist.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return blacklist, nil
+}
+
+// Contains returns true if the password is in the blacklist
+func (blacklist *BlacklistFile) Contains(password string) bool {
+	blacklist.mutex.RLock()
+	defer blacklist.mutex.RUnlock()
+
+	return blacklist.set.Contains(password)
+}
+
+// Reload loads the blacklist file again if it changed since it was last loaded, and returns true if it did.
+// The previous blacklist is kept if the file can't be loaded
+func (blacklist *BlacklistFile) Reload() (bool, error) {
+	info, err := os.Stat(blacklist.config.File)
+	if err != nil {
+		return false, err
+	}
+
+	blacklist.mutex.RLock()
+	changed := blacklist.set == nil || !info.ModTime().Equal(blacklist.modTime) || info.Size() != blacklist.size
+	blacklist.mutex.RUnlock()
+
+	if !changed {
+		return false, nil
+	}
+
+	set, err := loadPasswordSet(blacklist.config.File, blacklist.config.Filter, blacklist.config.FalsePositiveRate)
+	if err != nil {
+		return false, err
+	}
+
+	blacklist.mutex.Lock()
+	blacklist.set = set
+	blacklist.modTime = info.ModTime()
+	blacklist.size = info.Size()
+	blacklist.mutex.Unlock()
+
+	return true, nil
+}
+
+// Watch reloads the blacklist file every time it changes, it checks the file at the given interval and never returns
+func (blacklist *BlacklistFile) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		reloaded, err := blacklist.Reload()
+		if err != nil {
+			log.Println("could not reload blacklist " + blacklist.config.File + ": " + err.Error())
+		} else if reloaded {
+			log.Println("reloaded blacklist " + blacklist.config.File)
+		}
+	}
+}
+
+// loadPasswordSet reads the passwords of the file, one per line, into a hash set or a bloom filter
+func loadPasswordSet(filename string, filter string, falsePositiveRate float64) (PasswordSet, error) {
+	switch filter {
+	case config.SetFilter, "":
+		set := make(StringSet)
+		err := scanPasswords(filename, func(password string) {
+			set[password] = struct{}{}
+		})
+		return set, err
+	case config.BloomFilter:
+		// count the passwords first to size the filter, the file may be too large to keep in memory
+		n := 0
+		err := scanPasswords(filename, func(password string) {
+			n++
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		bloomFilter := NewBloomFilter(n, falsePositiveRate)
+		err = scanPasswords(filename, bloomFilter.Add)
+		return bloomFilter, err
+	}
+
+	return nil, errors.New("unknown blacklist filter " + filter)
+}
+
+// scanPasswords calls add with every non-empty line of the file
+func scanPasswords(filename string, add func(password string)) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if password := scanner.Text(); password != "" {
+			add(password)
 		}
 	}
-	return slice
+
+	return scanner.Err()
 }
 
 // LoadBlacklist loads a file of high-probability password e.g. ./data/rockyou-1k.txt
@@ -1,15 +1,100 @@
 package checkers
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 
+	"github.com/ppartarr/tipsy/config"
+	"github.com/ppartarr/tipsy/correctors"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestCheckBlacklist(t *testing.T) {
-	checker := NewChecker(testTypos, topCorrectors)
-	ball := checker.CheckBlacklist("password!", []string{"password"})
+	checker := NewService(testTypos, topCorrectors)
+	ball, err := checker.CheckBlacklist("password!", []string{"password"})
+	assert.Nil(t, err)
 	if !assert.ElementsMatch(t, ball, []string{"Password!", "PASSWORD!"}) {
 		t.Error("ball should be the set of strings containing the output of the correctors, unless it's in the blacklist")
 	}
 }
+
+func TestCheckBlacklistKeypressEdit(t *testing.T) {
+	checker := NewService(testTypos, []string{correctors.KeypressEdit})
+	ball, err := checker.CheckBlacklist("passsword", []string{"password"})
+	assert.Nil(t, err)
+	if !assert.NotContains(t, ball, "password") {
+		t.Error("ball should not contain the keypress edits in the blacklist")
+	}
+}
+
+func TestBlacklistFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blacklist")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "blacklist.txt")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("password\n123456\n"), 0644))
+
+	for _, filter := range []string{config.SetFilter, config.BloomFilter} {
+		blacklist, err := LoadBlacklistFile(&config.BlacklistChecker{File: file, Filter: filter, FalsePositiveRate: 0.001})
+		assert.Nil(t, err)
+		assert.True(t, blacklist.Contains("password"))
+		assert.False(t, blacklist.Contains("drowssap"))
+
+		reloaded, err := blacklist.Reload()
+		assert.Nil(t, err)
+		assert.False(t, reloaded, "the blacklist should only be reloaded when the file changes")
+	}
+
+	blacklist, err := LoadBlacklistFile(&config.BlacklistChecker{File: file, Filter: config.SetFilter})
+	assert.Nil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(file, []byte("password\n123456\nqwerty\n"), 0644))
+	reloaded, err := blacklist.Reload()
+	assert.Nil(t, err)
+	assert.True(t, reloaded)
+	assert.True(t, blacklist.Contains("qwerty"))
+
+	// the previous blacklist is kept if the file is removed
+	assert.Nil(t, os.Remove(file))
+	_, err = blacklist.Reload()
+	assert.NotNil(t, err)
+	assert.True(t, blacklist.Contains("qwerty"))
+}
+
+func TestBloomFilter(t *testing.T) {
+	filter := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.Add("password" + strconv.Itoa(i))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		assert.True(t, filter.Contains("password"+strconv.Itoa(i)), "a bloom filter has no false negatives")
+		if filter.Contains("drowssap" + strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 50)
+}
+
+func TestBlacklistVerify(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	credential := &Credential{PasswordHash: string(hash)}
+
+	checker := NewBlacklist(NewService(testTypos, topCorrectors), NewStringSet([]string{"password"}))
+
+	result, err := checker.Verify(context.Background(), "PASSWORD", credential)
+	assert.Nil(t, err)
+	assert.False(t, result.Match, "typos of a blacklisted password should not be corrected")
+
+	result, err = checker.Verify(context.Background(), "password", credential)
+	assert.Nil(t, err)
+	assert.True(t, result.Match)
+}
@@ -0,0 +1,72 @@
+package checkers
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// BloomFilter is a compact set of passwords for blacklists with millions of entries e.g. the full rockyou list.
+// Contains may return true for a password that was never added, with the configured false positive rate, but never
+// returns false for a password that was added. A false positive only removes a correction from the ball
+type BloomFilter struct {
+	bits   []uint64
+	m      uint64
+	hashes uint64
+}
+
+// NewBloomFilter returns a Bloom filter sized for n passwords with the given false positive rate e.g. 0.001
+func NewBloomFilter(n int, falsePositiveRate float64) *BloomFilter {
+	if n < 1 {
+		n = 1
+	}
+
+	// optimal number of bits and hash functions for n entries
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	hashes := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+
+	return &BloomFilter{
+		bits:   make([]uint64, (m+63)/64),
+		m:      m,
+		hashes: hashes,
+	}
+}
+
+// Add adds the password to the filter
+func (filter *BloomFilter) Add(password string) {
+	h1, h2 := bloomHash(password)
+	for i := uint64(0); i < filter.hashes; i++ {
+		bit := (h1 + i*h2) % filter.m
+		filter.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// Contains returns true if the password may have been added to the filter
+func (filter *BloomFilter) Contains(password string) bool {
+	h1, h2 := bloomHash(password)
+	for i := uint64(0); i < filter.hashes; i++ {
+		bit := (h1 + i*h2) % filter.m
+		if filter.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash returns the two hashes used to derive the k hash functions of the filter (Kirsch and Mitzenmacher)
+func bloomHash(password string) (uint64, uint64) {
+	hash := fnv.New64a()
+	hash.Write([]byte(password))
+	h1 := hash.Sum64()
+
+	// a second, independent hash from the same state
+	hash.Write([]byte{0})
+	h2 := hash.Sum64() | 1
+
+	return h1, h2
+}
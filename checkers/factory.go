@@ -0,0 +1,184 @@
+package checkers
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/ppartarr/tipsy/checkers/models"
+	"github.com/ppartarr/tipsy/checkers/typtop"
+	"github.com/ppartarr/tipsy/config"
+)
+
+// New returns the checker set in the checker section of the config, using the typos, correctors and corrector
+// options of the config
+func New(server *config.Server) (Checker, error) {
+	if server.Checker == nil {
+		return nil, errors.New("no checker is defined in config - use one of: always, blacklist, optimal, typtop, pipeline")
+	}
+
+	service := NewService(server.Typos, server.Correctors).WithOptions(server.CorrectorOptions())
+	constantWork := server.Ball != nil && server.Ball.ConstantWork
+	if constantWork {
+		service = service.WithConstantWork(server.Ball.MaxSize)
+	}
+	// the pool is shared by every login
+	if server.Checker.Workers > 0 {
+		service = service.WithPool(NewHashPool(server.Checker.Workers))
+	}
+
+	switch {
+	case server.Checker.Always:
+		return withStrength(NewAlways(service), service, server.Checker.Strength), nil
+	case server.Checker.Blacklist != nil:
+		stage, err := newBlacklistStage(server.Checker.Blacklist)
+		if err != nil {
+			return nil, err
+		}
+		return withStrength(NewBlacklist(service, stage.blacklist), service, server.Checker.Strength, stage), nil
+	case server.Checker.Optimal != nil:
+		stage, err := newOptimalStage(server.Checker.Optimal, server.MinPasswordLength)
+		if err != nil {
+			return nil, err
+		}
+		return withStrength(NewOptimal(service, stage.list, stage.q, stage.model), service, server.Checker.Strength, stage), nil
+	case len(server.Checker.Pipeline) > 0:
+		stages := make([]Stage, 0, len(server.Checker.Pipeline))
+		for _, stageConfig := range server.Checker.Pipeline {
+			stage, err := newStage(stageConfig, server.MinPasswordLength)
+			if err != nil {
+				return nil, err
+			}
+			stages = append(stages, stage)
+		}
+		return NewPipeline(service, stages...), nil
+	case server.Checker.TypTop != nil:
+		return NewTypTop(typtop.NewChecker(server.Checker.TypTop, server.Typos).WithOptions(server.CorrectorOptions()).WithConstantWork(constantWork)), nil
+	}
+
+	return nil, errors.New("no checker is defined in config - use one of: always, blacklist, optimal, typtop, pipeline")
+}
+
+// withStrength returns the checker, or a pipeline of the same stages followed by a strength stage if the strength filter
+// is set
+func withStrength(checker Checker, service *Service, strength *config.StrengthFilter, stages ...Stage) Checker {
+	if strength == nil {
+		return checker
+	}
+
+	return NewPipeline(service, append(stages, NewStrengthStage(strength.Zxcvbn, strength.MinGuessesLog10))...)
+}
+
+// newStage returns the stage of the pipeline set in the stage config
+func newStage(stageConfig config.Stage, minPasswordLength int) (Stage, error) {
+	switch {
+	case stageConfig.Blacklist != nil:
+		return newBlacklistStage(stageConfig.Blacklist)
+	case stageConfig.Optimal != nil:
+		return newOptimalStage(stageConfig.Optimal, minPasswordLength)
+	case stageConfig.Strength != nil:
+		return NewStrengthStage(stageConfig.Strength.Zxcvbn, stageConfig.Strength.MinGuessesLog10), nil
+	}
+
+	return nil, errors.New("no filter is defined in pipeline stage - use one of: blacklist, optimal, strength")
+}
+
+// newBlacklistStage loads the blacklist once, and reloads it when the file changes
+func newBlacklistStage(blacklistConfig *config.BlacklistChecker) (*BlacklistStage, error) {
+	blacklist, err := LoadBlacklistFile(blacklistConfig)
+	if err != nil {
+		return nil, err
+	}
+	if blacklistConfig.ReloadInterval > 0 {
+		go blacklist.Watch(blacklistConfig.ReloadInterval)
+	}
+	return NewBlacklistStage(blacklist), nil
+}
+
+// newOptimalStage loads the password model and the frequency list of the optimal checker once
+func newOptimalStage(optimalConfig *config.OptimalChecker, minPasswordLength int) (*OptimalStage, error) {
+	var model models.PasswordModel
+	if optimalConfig.Model != nil {
+		var err error
+		model, err = loadPasswordModel(optimalConfig.Model, minPasswordLength)
+		if err != nil {
+			return nil, err
+		}
+	}
+	list, err := loadFrequencyList(optimalConfig, minPasswordLength)
+	if err != nil {
+		return nil, err
+	}
+	if optimalConfig.Smoothing != nil {
+		list, err = NewSmoothedFrequencyList(list, optimalConfig.Smoothing.Type, optimalConfig.Smoothing.Alpha)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewOptimalStage(list, optimalConfig.QthMostProbablePassword, model), nil
+}
+
+// loadFrequencyList loads the frequency list of the optimal checker once, from the index if there is one. The index is
+// built from the frequency list file if it doesn't exist, is older than the file or is invalid
+func loadFrequencyList(optimalConfig *config.OptimalChecker, minPasswordLength int) (FrequencyList, error) {
+	if optimalConfig.Index == "" {
+		return NewFrequencyList(LoadFrequencyBlacklist(optimalConfig.File, minPasswordLength)), nil
+	}
+
+	fileInfo, err := os.Stat(optimalConfig.File)
+	if err != nil {
+		return nil, err
+	}
+
+	indexInfo, err := os.Stat(optimalConfig.Index)
+	if err != nil || indexInfo.ModTime().Before(fileInfo.ModTime()) {
+		log.Println("building frequency index " + optimalConfig.Index + " of " + optimalConfig.File)
+		err = BuildFrequencyIndex(LoadFrequencyBlacklist(optimalConfig.File, minPasswordLength), optimalConfig.Index)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	index, err := OpenFrequencyIndex(optimalConfig.Index)
+	if err != nil {
+		log.Println(err.Error() + ", rebuilding frequency index " + optimalConfig.Index + " of " + optimalConfig.File)
+		err = BuildFrequencyIndex(LoadFrequencyBlacklist(optimalConfig.File, minPasswordLength), optimalConfig.Index)
+		if err != nil {
+			return nil, err
+		}
+		return OpenFrequencyIndex(optimalConfig.Index)
+	}
+
+	return index, nil
+}
+
+// loadPasswordModel loads the trained password model, or trains it and saves it if the model file doesn't exist yet
+func loadPasswordModel(modelConfig *config.PasswordModel, minPasswordLength int) (models.PasswordModel, error) {
+	if modelConfig.File != "" {
+		if _, err := os.Stat(modelConfig.File); err == nil {
+			model, err := models.Load(modelConfig.File)
+			if err != nil {
+				return nil, err
+			}
+			if model.Name() != modelConfig.Type {
+				return nil, errors.New("password model " + modelConfig.File + " is a " + model.Name() + " model, not " + modelConfig.Type)
+			}
+			return model, nil
+		}
+	}
+
+	log.Println("training " + modelConfig.Type + " password model on " + modelConfig.Training)
+	model, err := models.Train(modelConfig.Type, LoadFrequencyBlacklist(modelConfig.Training, minPasswordLength), modelConfig.N)
+	if err != nil {
+		return nil, err
+	}
+
+	if modelConfig.File != "" {
+		err = models.Save(model, modelConfig.File)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return model, nil
+}
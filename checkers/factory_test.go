@@ -0,0 +1,79 @@
+package checkers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ppartarr/tipsy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	server := &config.Server{
+		Typos:      testTypos,
+		Correctors: topCorrectors,
+		Checker:    &config.Checker{Always: true},
+	}
+	checker, err := New(server)
+	assert.Nil(t, err)
+	assert.IsType(t, &Always{}, checker)
+
+	server.Checker = &config.Checker{Blacklist: &config.BlacklistChecker{File: "../data/rockyou-1k.txt"}}
+	checker, err = New(server)
+	assert.Nil(t, err)
+	assert.IsType(t, &Blacklist{}, checker)
+
+	// the weak passwords of the ball of the always and blacklist checkers are removed by a strength stage
+	server.Checker = &config.Checker{Always: true, Strength: &config.StrengthFilter{MinGuessesLog10: 6}}
+	checker, err = New(server)
+	assert.Nil(t, err)
+	assert.Len(t, checker.(*Pipeline).stages, 1)
+
+	server.Checker = &config.Checker{Blacklist: &config.BlacklistChecker{File: "../data/rockyou-1k.txt"}, Strength: &config.StrengthFilter{MinGuessesLog10: 6}}
+	checker, err = New(server)
+	assert.Nil(t, err)
+	assert.Equal(t, "blacklist", checker.(*Pipeline).stages[0].Name())
+	assert.Equal(t, "strength", checker.(*Pipeline).stages[1].Name())
+
+	server.Checker = &config.Checker{Optimal: &config.OptimalChecker{File: "../data/rockyou-1k-withcount.txt", QthMostProbablePassword: 1000}}
+	checker, err = New(server)
+	assert.Nil(t, err)
+	assert.IsType(t, &Optimal{}, checker)
+
+	// the password model is trained once and saved
+	dir, err := ioutil.TempDir("", "models")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	modelFile := filepath.Join(dir, "ngram.gob")
+	server.Checker.Optimal.Model = &config.PasswordModel{Type: "ngram", File: modelFile, Training: "../data/rockyou-1k-withcount.txt", N: 2}
+	checker, err = New(server)
+	assert.Nil(t, err)
+	assert.NotNil(t, checker.(*Optimal).model)
+	assert.FileExists(t, modelFile)
+
+	checker, err = New(server)
+	assert.Nil(t, err)
+	assert.Equal(t, "ngram", checker.(*Optimal).model.Name())
+
+	server.Checker = &config.Checker{Pipeline: []config.Stage{
+		{Blacklist: &config.BlacklistChecker{File: "../data/rockyou-1k.txt"}},
+		{Optimal: &config.OptimalChecker{File: "../data/rockyou-1k-withcount.txt", QthMostProbablePassword: 1000}},
+		{Strength: &config.StrengthFilter{Zxcvbn: 2, MinGuessesLog10: 6}},
+	}}
+	checker, err = New(server)
+	assert.Nil(t, err)
+	assert.IsType(t, &Pipeline{}, checker)
+	assert.Len(t, checker.(*Pipeline).stages, 3)
+
+	server.Checker = &config.Checker{TypTop: &config.TypTopChecker{}}
+	checker, err = New(server)
+	assert.Nil(t, err)
+	assert.IsType(t, &TypTop{}, checker)
+
+	server.Checker = &config.Checker{}
+	_, err = New(server)
+	assert.NotNil(t, err)
+}
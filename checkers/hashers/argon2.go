@@ -0,0 +1,97 @@
+package hashers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2idHasher hashes passwords with argon2id e.g. $argon2id$v=19$m=65536,t=3,p=4$salt$hash
+type Argon2idHasher struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	keyLength   uint32
+}
+
+// NewArgon2id initialises the argon2id hasher, memory is in KiB
+func NewArgon2id(memory uint32, iterations uint32, parallelism uint8, keyLength uint32) *Argon2idHasher {
+	return &Argon2idHasher{
+		memory:      memory,
+		iterations:  iterations,
+		parallelism: parallelism,
+		keyLength:   keyLength,
+	}
+}
+
+// Name returns argon2id
+func (hasher *Argon2idHasher) Name() string {
+	return Argon2id
+}
+
+// Hash returns the PHC string of the argon2id hash of the password
+func (hasher *Argon2idHasher) Hash(password string) (string, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, hasher.iterations, hasher.memory, hasher.parallelism, hasher.keyLength)
+
+	parameters := []string{
+		"m=" + strconv.FormatUint(uint64(hasher.memory), 10),
+		"t=" + strconv.FormatUint(uint64(hasher.iterations), 10),
+		"p=" + strconv.FormatUint(uint64(hasher.parallelism), 10),
+	}
+	return encodePHC(Argon2id, strconv.Itoa(argon2.Version), parameters, salt, key), nil
+}
+
+// NeedsRehash returns true if the hash isn't an argon2id hash with at least the parameters of the hasher
+func (hasher *Argon2idHasher) NeedsRehash(hash string) bool {
+	parsed, err := parsePHC(hash)
+	if err != nil || parsed.id != Argon2id || parsed.version != strconv.Itoa(argon2.Version) {
+		return true
+	}
+
+	memory, errM := parsed.intParameter("m")
+	iterations, errT := parsed.intParameter("t")
+	parallelism, errP := parsed.intParameter("p")
+	if errM != nil || errT != nil || errP != nil {
+		return true
+	}
+
+	return uint32(memory) < hasher.memory || uint32(iterations) < hasher.iterations || parallelism < int(hasher.parallelism) ||
+		uint32(len(parsed.hash)) < hasher.keyLength
+}
+
+func verifyArgon2id(password string, hash string) (bool, error) {
+	parsed, err := parsePHC(hash)
+	if err != nil {
+		return false, err
+	}
+
+	if parsed.version != strconv.Itoa(argon2.Version) {
+		return false, errors.New("unsupported argon2id version " + parsed.version)
+	}
+
+	memory, err := parsed.boundedParameter("m", minArgon2idMemory, int(limits.Argon2idMemory))
+	if err != nil {
+		return false, err
+	}
+
+	iterations, err := parsed.boundedParameter("t", 1, int(limits.Argon2idIterations))
+	if err != nil {
+		return false, err
+	}
+
+	parallelism, err := parsed.boundedParameter("p", 1, int(limits.Argon2idParallelism))
+	if err != nil {
+		return false, err
+	}
+
+	key := argon2.IDKey([]byte(password), parsed.salt, uint32(iterations), uint32(memory), uint8(parallelism), uint32(len(parsed.hash)))
+
+	return subtle.ConstantTimeCompare(key, parsed.hash) == 1, nil
+}
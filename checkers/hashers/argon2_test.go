@@ -0,0 +1,24 @@
+package hashers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a stored argon2id hash that was truncated or tampered with must not verify every password
+func TestVerifyArgon2idTampered(t *testing.T) {
+	for description, hash := range map[string]string{
+		"an empty hash":                  "$argon2id$v=19$m=1024,t=1,p=1$dGlwc3lzYWx0c2FsdDEyMw$",
+		"a truncated hash":               "$argon2id$v=19$m=1024,t=1,p=1$dGlwc3lzYWx0c2FsdDEyMw$aGFzaGhhc2g",
+		"a short salt":                   "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$LKOPuzBtE+ir5nx9EI+xmrNKqq01L0JZ5s2m5WWcLWQ",
+		"an empty hash and a short salt": "$argon2id$v=19$m=8,t=1,p=1$c2FsdHNhbHQ$",
+		"too little memory":              "$argon2id$v=19$m=8,t=1,p=1$dGlwc3lzYWx0c2FsdDEyMw$LKOPuzBtE+ir5nx9EI+xmrNKqq01L0JZ5s2m5WWcLWQ",
+	} {
+		for _, password := range []string{"password", ""} {
+			match, err := Verify(password, hash)
+			assert.NotNil(t, err, description)
+			assert.False(t, match, description)
+		}
+	}
+}
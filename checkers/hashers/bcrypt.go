@@ -0,0 +1,40 @@
+package hashers
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptHasher hashes passwords with bcrypt, its modular crypt format e.g. $2a$14$... is used as is
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcrypt initialises the bcrypt hasher with the log2 of the number of rounds
+func NewBcrypt(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+// Name returns bcrypt
+func (hasher *BcryptHasher) Name() string {
+	return Bcrypt
+}
+
+// Hash returns the bcrypt hash of the password
+func (hasher *BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), hasher.cost)
+	return string(bytes), err
+}
+
+// NeedsRehash returns true if the hash isn't a bcrypt hash with at least the cost of the hasher
+func (hasher *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < hasher.cost
+}
+
+func verifyBcrypt(password string, hash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	return err == nil, err
+}
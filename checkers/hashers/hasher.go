@@ -0,0 +1,161 @@
+package hashers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ppartarr/tipsy/config"
+)
+
+// the password hashers
+const (
+	Bcrypt   = "bcrypt"
+	Scrypt   = "scrypt"
+	Argon2id = "argon2id"
+	PBKDF2   = "pbkdf2"
+)
+
+// saltLength is the length in bytes of the random salt of scrypt, argon2id and pbkdf2
+const saltLength = 16
+
+// minSaltLength and minKeyLength are the minimum lengths in bytes of the salt and the hash of a PHC string, the length
+// of the derived key is read from the hash so an empty or truncated hash would match every password
+const (
+	minSaltLength = 16
+	minKeyLength  = 16
+)
+
+// PasswordHasher hashes passwords into PHC strings e.g. $argon2id$v=19$m=65536,t=3,p=4$salt$hash
+type PasswordHasher interface {
+	// Name returns the name of the hasher e.g. argon2id
+	Name() string
+	// Hash returns the PHC string of the password, with a random salt
+	Hash(password string) (string, error)
+	// NeedsRehash returns true if the hash is of another hasher, or if its parameters are weaker than the hasher's
+	NeedsRehash(hash string) bool
+}
+
+// New returns the hasher set in the hasher config, bcrypt with a cost of 14 if it's nil
+func New(hasherConfig *config.Hasher) (PasswordHasher, error) {
+	switch {
+	case hasherConfig == nil:
+		return NewBcrypt(14), nil
+	case hasherConfig.Bcrypt != nil:
+		return NewBcrypt(hasherConfig.Bcrypt.Cost), nil
+	case hasherConfig.Scrypt != nil:
+		return NewScrypt(hasherConfig.Scrypt.LogN, hasherConfig.Scrypt.BlockSize, hasherConfig.Scrypt.Parallelism, hasherConfig.Scrypt.KeyLength), nil
+	case hasherConfig.Argon2id != nil:
+		return NewArgon2id(hasherConfig.Argon2id.Memory, hasherConfig.Argon2id.Iterations, hasherConfig.Argon2id.Parallelism, hasherConfig.Argon2id.KeyLength), nil
+	case hasherConfig.PBKDF2 != nil:
+		return NewPBKDF2(hasherConfig.PBKDF2.Digest, hasherConfig.PBKDF2.Iterations, hasherConfig.PBKDF2.KeyLength)
+	}
+
+	return nil, errors.New("no hasher is defined in config - use one of: " + Bcrypt + ", " + Scrypt + ", " + Argon2id + ", " + PBKDF2)
+}
+
+// Verify checks the password against a hash of any supported format, the parameters of the hash are read from the
+// hash so that hashes imported from other systems can be verified
+func Verify(password string, hash string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return verifyBcrypt(password, hash)
+	case strings.HasPrefix(hash, "$"+Scrypt+"$"):
+		return verifyScrypt(password, hash)
+	case strings.HasPrefix(hash, "$"+Argon2id+"$"):
+		return verifyArgon2id(password, hash)
+	case strings.HasPrefix(hash, "$"+PBKDF2+"-"):
+		return verifyPBKDF2(password, hash)
+	}
+
+	return false, errors.New("unknown password hash format")
+}
+
+// phc is a hash in the PHC string format $id[$v=version][$param=value(,param=value)*][$salt[$hash]]
+type phc struct {
+	id         string
+	version    string
+	parameters map[string]string
+	salt       []byte
+	hash       []byte
+}
+
+// parsePHC parses a PHC string with a salt and a hash, the salt and the hash are base64 without padding
+func parsePHC(encoded string) (*phc, error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) < 5 || len(fields) > 6 || fields[0] != "" {
+		return nil, errors.New("malformed PHC string")
+	}
+
+	parsed := &phc{id: fields[1], parameters: make(map[string]string)}
+	fields = fields[2:]
+
+	if strings.HasPrefix(fields[0], "v=") {
+		parsed.version = strings.TrimPrefix(fields[0], "v=")
+		fields = fields[1:]
+	}
+
+	if len(fields) != 3 {
+		return nil, errors.New("malformed PHC string")
+	}
+
+	for _, parameter := range strings.Split(fields[0], ",") {
+		pair := strings.SplitN(parameter, "=", 2)
+		if len(pair) != 2 {
+			return nil, errors.New("malformed PHC parameter " + parameter)
+		}
+		parsed.parameters[pair[0]] = pair[1]
+	}
+
+	var err error
+	parsed.salt, err = base64.RawStdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.hash, err = base64.RawStdEncoding.DecodeString(fields[2])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parsed.salt) < minSaltLength || len(parsed.hash) < minKeyLength {
+		return nil, errors.New("the salt and the hash of the PHC string must be at least " + strconv.Itoa(minSaltLength) + " bytes")
+	}
+
+	return parsed, nil
+}
+
+// intParameter returns the value of an integer parameter of the PHC string
+func (parsed *phc) intParameter(name string) (int, error) {
+	value, ok := parsed.parameters[name]
+	if !ok {
+		return 0, errors.New("PHC string has no parameter " + name)
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, errors.New("PHC parameter " + name + " must be a positive integer")
+	}
+
+	return number, nil
+}
+
+// encodePHC returns the PHC string of a hash, the parameters are in the given order
+func encodePHC(id string, version string, parameters []string, salt []byte, hash []byte) string {
+	fields := []string{"", id}
+	if version != "" {
+		fields = append(fields, "v="+version)
+	}
+	fields = append(fields, strings.Join(parameters, ","))
+	fields = append(fields, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash))
+	return strings.Join(fields, "$")
+}
+
+// newSalt returns a random salt
+func newSalt() ([]byte, error) {
+	salt := make([]byte, saltLength)
+	_, err := rand.Read(salt)
+	return salt, err
+}
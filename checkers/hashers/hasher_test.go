@@ -0,0 +1,119 @@
+package hashers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppartarr/tipsy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashers(t *testing.T) {
+	pbkdf2, err := NewPBKDF2("sha512", 1000, 32)
+	assert.Nil(t, err)
+
+	for _, hasher := range []PasswordHasher{NewBcrypt(4), NewScrypt(10, 8, 1, 32), NewArgon2id(1024, 1, 1, 32), pbkdf2} {
+		hash, err := hasher.Hash("password")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$"))
+
+		match, err := Verify("password", hash)
+		assert.Nil(t, err)
+		assert.True(t, match, "%s should verify its own hash", hasher.Name())
+
+		match, err = Verify("Password", hash)
+		assert.Nil(t, err)
+		assert.False(t, match, "%s should not verify another password", hasher.Name())
+
+		other, err := hasher.Hash("password")
+		assert.Nil(t, err)
+		assert.NotEqual(t, hash, other, "%s should use a random salt", hasher.Name())
+	}
+}
+
+// hashes imported from other systems, computed with python's hashlib
+func TestVerifyImported(t *testing.T) {
+	for _, hash := range []string{
+		"$pbkdf2-sha256$i=1000$dGlwc3lzYWx0c2FsdDEyMw$LKOPuzBtE+ir5nx9EI+xmrNKqq01L0JZ5s2m5WWcLWQ",
+		"$scrypt$ln=10,r=8,p=1$dGlwc3lzYWx0c2FsdDEyMw$YGERL8JO7SW997L0reOfMSbo0czCzgsocE4SVwBGtok",
+	} {
+		match, err := Verify("password", hash)
+		assert.Nil(t, err)
+		assert.True(t, match, hash)
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"password",
+		"$md5$salt$hash",
+		"$scrypt$ln=10,r=8$dGlwc3lzYWx0c2FsdDEyMw$YGERL8JO7SW997L0reOfMSbo0czCzgsocE4SVwBGtok",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$pbkdf2-md5$i=1000$c2FsdA$aGFzaA",
+		// parameters above the limits
+		"$argon2id$v=19$m=4194304,t=1,p=1$c2FsdA$aGFzaA",
+		"$scrypt$ln=30,r=8,p=1$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=1000000000$c2FsdA$aGFzaA",
+	} {
+		_, err := Verify("password", hash)
+		assert.NotNil(t, err, hash)
+	}
+}
+
+func TestNew(t *testing.T) {
+	hasher, err := New(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Bcrypt, hasher.Name())
+
+	hasher, err = New(&config.Hasher{Argon2id: &config.Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, KeyLength: 16}})
+	assert.Nil(t, err)
+	assert.Equal(t, Argon2id, hasher.Name())
+
+	hash, err := hasher.Hash("password")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	_, err = New(&config.Hasher{PBKDF2: &config.PBKDF2Hasher{Digest: "md5"}})
+	assert.NotNil(t, err)
+}
+
+func TestNeedsRehash(t *testing.T) {
+	weak, err := NewBcrypt(4).Hash("password")
+	assert.Nil(t, err)
+
+	assert.False(t, NewBcrypt(4).NeedsRehash(weak))
+	assert.True(t, NewBcrypt(5).NeedsRehash(weak), "a lower bcrypt cost should be rehashed")
+	assert.True(t, NewArgon2id(1024, 1, 1, 32).NeedsRehash(weak), "another algorithm should be rehashed")
+
+	argon2id, err := NewArgon2id(1024, 1, 1, 32).Hash("password")
+	assert.Nil(t, err)
+	assert.False(t, NewArgon2id(1024, 1, 1, 32).NeedsRehash(argon2id))
+	assert.False(t, NewArgon2id(512, 1, 1, 32).NeedsRehash(argon2id), "stronger parameters should not be rehashed")
+	assert.True(t, NewArgon2id(2048, 1, 1, 32).NeedsRehash(argon2id))
+
+	scrypt := "$scrypt$ln=10,r=8,p=1$dGlwc3lzYWx0c2FsdDEyMw$YGERL8JO7SW997L0reOfMSbo0czCzgsocE4SVwBGtok"
+	assert.False(t, NewScrypt(10, 8, 1, 32).NeedsRehash(scrypt))
+	assert.True(t, NewScrypt(15, 8, 1, 32).NeedsRehash(scrypt))
+
+	pbkdf2 := "$pbkdf2-sha256$i=1000$dGlwc3lzYWx0c2FsdDEyMw$LKOPuzBtE+ir5nx9EI+xmrNKqq01L0JZ5s2m5WWcLWQ"
+	sha256, err := NewPBKDF2("sha256", 1000, 32)
+	assert.Nil(t, err)
+	assert.False(t, sha256.NeedsRehash(pbkdf2))
+	sha512, err := NewPBKDF2("sha512", 1000, 32)
+	assert.Nil(t, err)
+	assert.True(t, sha512.NeedsRehash(pbkdf2), "another digest should be rehashed")
+
+	assert.True(t, NewBcrypt(4).NeedsRehash("malformed"))
+}
+
+func TestSetLimits(t *testing.T) {
+	defer SetLimits(nil)
+
+	hash, err := NewArgon2id(1024, 1, 1, 32).Hash("password")
+	assert.Nil(t, err)
+
+	SetLimits(&config.HasherLimits{Argon2idMemory: 512, Argon2idIterations: 1, Argon2idParallelism: 1})
+	_, err = Verify("password", hash)
+	assert.NotNil(t, err, "hashes above the configured limits should not be verified")
+}
@@ -0,0 +1,80 @@
+package hashers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ppartarr/tipsy/config"
+)
+
+// Limits are the maximum parameters of the hashes that are verified, a hash imported from another system with a huge
+// cost would otherwise make every login allocate gigabytes of memory or hash for minutes
+type Limits struct {
+	// Argon2idMemory is in KiB
+	Argon2idMemory      uint32
+	Argon2idIterations  uint32
+	Argon2idParallelism uint8
+	ScryptLogN          int
+	ScryptBlockSize     int
+	ScryptParallelism   int
+	PBKDF2Iterations    int
+}
+
+// DefaultLimits are the limits used until SetLimits is called
+var DefaultLimits = Limits{
+	Argon2idMemory:      256 * 1024,
+	Argon2idIterations:  10,
+	Argon2idParallelism: 16,
+	ScryptLogN:          20,
+	ScryptBlockSize:     16,
+	ScryptParallelism:   16,
+	PBKDF2Iterations:    10000000,
+}
+
+var limits = DefaultLimits
+
+// the minimum parameters of the hashes that are verified, a hash that was tampered with to a tiny cost is as easy to
+// match as no hash
+const (
+	minArgon2idMemory   = 1024
+	minScryptLogN       = 10
+	minPBKDF2Iterations = 1000
+)
+
+// SetLimits sets the limits of the hashes verified by Verify, it must be called before the first login. The default
+// limits are kept if the config is nil
+func SetLimits(limitsConfig *config.HasherLimits) {
+	if limitsConfig == nil {
+		limits = DefaultLimits
+		return
+	}
+
+	limits = Limits{
+		Argon2idMemory:      limitsConfig.Argon2idMemory,
+		Argon2idIterations:  limitsConfig.Argon2idIterations,
+		Argon2idParallelism: limitsConfig.Argon2idParallelism,
+		ScryptLogN:          limitsConfig.ScryptLogN,
+		ScryptBlockSize:     limitsConfig.ScryptBlockSize,
+		ScryptParallelism:   limitsConfig.ScryptParallelism,
+		PBKDF2Iterations:    limitsConfig.PBKDF2Iterations,
+	}
+}
+
+// boundedParameter returns the value of an integer parameter of the PHC string, or an error if it's below the minimum
+// or above the limit
+func (parsed *phc) boundedParameter(name string, minimum int, limit int) (int, error) {
+	value, err := parsed.intParameter(name)
+	if err != nil {
+		return 0, err
+	}
+
+	if value < minimum {
+		return 0, errors.New("PHC parameter " + name + " of " + parsed.id + " is below the minimum of " + strconv.Itoa(minimum))
+	}
+
+	if value > limit {
+		return 0, errors.New("PHC parameter " + name + " of " + parsed.id + " is above the limit of " + strconv.Itoa(limit))
+	}
+
+	return value, nil
+}
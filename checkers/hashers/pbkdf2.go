@@ -0,0 +1,100 @@
+package hashers
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PBKDF2Hasher hashes passwords with pbkdf2 e.g. $pbkdf2-sha256$i=310000$salt$hash
+type PBKDF2Hasher struct {
+	digest     string
+	iterations int
+	keyLength  int
+}
+
+// NewPBKDF2 initialises the pbkdf2 hasher, digest is the HMAC digest, one of sha256 or sha512
+func NewPBKDF2(digest string, iterations int, keyLength int) (*PBKDF2Hasher, error) {
+	_, err := pbkdf2Digest(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PBKDF2Hasher{
+		digest:     digest,
+		iterations: iterations,
+		keyLength:  keyLength,
+	}, nil
+}
+
+// Name returns pbkdf2
+func (hasher *PBKDF2Hasher) Name() string {
+	return PBKDF2
+}
+
+// Hash returns the PHC string of the pbkdf2 hash of the password
+func (hasher *PBKDF2Hasher) Hash(password string) (string, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return "", err
+	}
+
+	digest, _ := pbkdf2Digest(hasher.digest)
+	key := pbkdf2.Key([]byte(password), salt, hasher.iterations, hasher.keyLength, digest)
+
+	return encodePHC(PBKDF2+"-"+hasher.digest, "", []string{"i=" + strconv.Itoa(hasher.iterations)}, salt, key), nil
+}
+
+// NeedsRehash returns true if the hash isn't a pbkdf2 hash with the digest and at least the parameters of the hasher
+func (hasher *PBKDF2Hasher) NeedsRehash(hash string) bool {
+	parsed, err := parsePHC(hash)
+	if err != nil || parsed.id != PBKDF2+"-"+hasher.digest {
+		return true
+	}
+
+	iterations, err := parsed.intParameter("i")
+	if err != nil {
+		return true
+	}
+
+	return iterations < hasher.iterations || len(parsed.hash) < hasher.keyLength
+}
+
+func verifyPBKDF2(password string, hash string) (bool, error) {
+	parsed, err := parsePHC(hash)
+	if err != nil {
+		return false, err
+	}
+
+	digest, err := pbkdf2Digest(strings.TrimPrefix(parsed.id, PBKDF2+"-"))
+	if err != nil {
+		return false, err
+	}
+
+	iterations, err := parsed.boundedParameter("i", minPBKDF2Iterations, limits.PBKDF2Iterations)
+	if err != nil {
+		return false, err
+	}
+
+	key := pbkdf2.Key([]byte(password), parsed.salt, iterations, len(parsed.hash), digest)
+
+	return subtle.ConstantTimeCompare(key, parsed.hash) == 1, nil
+}
+
+// pbkdf2Digest returns the HMAC digest of pbkdf2
+func pbkdf2Digest(digest string) (func() hash.Hash, error) {
+	switch digest {
+	case "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	}
+
+	return nil, errors.New("unknown pbkdf2 digest " + digest + " - use one of: sha256, sha512")
+}
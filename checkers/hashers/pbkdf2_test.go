@@ -0,0 +1,24 @@
+package hashers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a stored pbkdf2 hash that was truncated or tampered with must not verify every password
+func TestVerifyPBKDF2Tampered(t *testing.T) {
+	for description, hash := range map[string]string{
+		"an empty hash":                  "$pbkdf2-sha256$i=1000$dGlwc3lzYWx0c2FsdDEyMw$",
+		"a truncated hash":               "$pbkdf2-sha256$i=1000$dGlwc3lzYWx0c2FsdDEyMw$aGFzaGhhc2g",
+		"a short salt":                   "$pbkdf2-sha256$i=1000$c2FsdHNhbHQ$LKOPuzBtE+ir5nx9EI+xmrNKqq01L0JZ5s2m5WWcLWQ",
+		"an empty hash and a short salt": "$pbkdf2-sha256$i=1$c2FsdHNhbHQ$",
+		"too few iterations":             "$pbkdf2-sha256$i=1$dGlwc3lzYWx0c2FsdDEyMw$LKOPuzBtE+ir5nx9EI+xmrNKqq01L0JZ5s2m5WWcLWQ",
+	} {
+		for _, password := range []string{"password", ""} {
+			match, err := Verify(password, hash)
+			assert.NotNil(t, err, description)
+			assert.False(t, match, description)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package hashers
+
+import (
+	"crypto/subtle"
+	"strconv"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ScryptHasher hashes passwords with scrypt e.g. $scrypt$ln=15,r=8,p=1$salt$hash
+type ScryptHasher struct {
+	logN        int
+	blockSize   int
+	parallelism int
+	keyLength   int
+}
+
+// NewScrypt initialises the scrypt hasher, logN is the log2 of the CPU and memory cost
+func NewScrypt(logN int, blockSize int, parallelism int, keyLength int) *ScryptHasher {
+	return &ScryptHasher{
+		logN:        logN,
+		blockSize:   blockSize,
+		parallelism: parallelism,
+		keyLength:   keyLength,
+	}
+}
+
+// Name returns scrypt
+func (hasher *ScryptHasher) Name() string {
+	return Scrypt
+}
+
+// Hash returns the PHC string of the scrypt hash of the password
+func (hasher *ScryptHasher) Hash(password string) (string, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return "", err
+	}
+
+	key, err := scrypt.Key([]byte(password), salt, 1<<hasher.logN, hasher.blockSize, hasher.parallelism, hasher.keyLength)
+	if err != nil {
+		return "", err
+	}
+
+	parameters := []string{
+		"ln=" + strconv.Itoa(hasher.logN),
+		"r=" + strconv.Itoa(hasher.blockSize),
+		"p=" + strconv.Itoa(hasher.parallelism),
+	}
+	return encodePHC(Scrypt, "", parameters, salt, key), nil
+}
+
+// NeedsRehash returns true if the hash isn't a scrypt hash with at least the parameters of the hasher
+func (hasher *ScryptHasher) NeedsRehash(hash string) bool {
+	parsed, err := parsePHC(hash)
+	if err != nil || parsed.id != Scrypt {
+		return true
+	}
+
+	logN, errN := parsed.intParameter("ln")
+	blockSize, errR := parsed.intParameter("r")
+	parallelism, errP := parsed.intParameter("p")
+	if errN != nil || errR != nil || errP != nil {
+		return true
+	}
+
+	return logN < hasher.logN || blockSize < hasher.blockSize || parallelism < hasher.parallelism || len(parsed.hash) < hasher.keyLength
+}
+
+func verifyScrypt(password string, hash string) (bool, error) {
+	parsed, err := parsePHC(hash)
+	if err != nil {
+		return false, err
+	}
+
+	logN, err := parsed.boundedParameter("ln", minScryptLogN, limits.ScryptLogN)
+	if err != nil {
+		return false, err
+	}
+
+	blockSize, err := parsed.boundedParameter("r", 1, limits.ScryptBlockSize)
+	if err != nil {
+		return false, err
+	}
+
+	parallelism, err := parsed.boundedParameter("p", 1, limits.ScryptParallelism)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := scrypt.Key([]byte(password), parsed.salt, 1<<logN, blockSize, parallelism, len(parsed.hash))
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(key, parsed.hash) == 1, nil
+}
@@ -0,0 +1,298 @@
+package checkers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/ppartarr/tipsy/checkers/models"
+)
+
+// the frequency index starts with the magic string, the number of passwords and the total frequency, then the offsets
+// of the passwords sorted in byte order, their frequencies, the frequencies sorted in decreasing order for the rank
+// of each password, and the passwords
+const (
+	indexMagic      = "TIPSYFQ1"
+	indexHeaderSize = 24
+)
+
+// FrequencyList is a distribution of high-probability passwords used by the optimal checker, it's also the histogram
+// model of the distribution
+type FrequencyList interface {
+	models.PasswordModel
+	// QthProbability returns the probability of the qth most probable password of the list
+	QthProbability(q int) (float64, error)
+	// Frequency returns the frequency of the password, 0 if it isn't in the list
+	Frequency(password string) int
+	// FrequencyOfRank returns the frequency of the password of the given rank, from 0 for the most probable password
+	FrequencyOfRank(rank int) int
+	// Len returns the number of passwords in the list
+	Len() int
+	// Total returns the sum of the frequencies of the passwords in the list
+	Total() int
+}
+
+// frequencyMap is a frequency list loaded in memory with its total and rank precomputed
+type frequencyMap struct {
+	frequencies map[string]int
+	total       int
+	descending  []int
+}
+
+// NewFrequencyList returns the frequency list of a frequency blacklist e.g. loaded with LoadFrequencyBlacklist
+func NewFrequencyList(frequencyBlacklist map[string]int) FrequencyList {
+	descending := make([]int, 0, len(frequencyBlacklist))
+	for _, frequency := range frequencyBlacklist {
+		descending = append(descending, frequency)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(descending)))
+
+	return &frequencyMap{
+		frequencies: frequencyBlacklist,
+		total:       TotalNumberOfPasswords(frequencyBlacklist),
+		descending:  descending,
+	}
+}
+
+func (list *frequencyMap) Name() string {
+	return models.Histogram
+}
+
+func (list *frequencyMap) Probability(password string) float64 {
+	frequency, ok := list.frequencies[password]
+	if !ok {
+		return 0
+	}
+	return float64(frequency) / float64(list.total)
+}
+
+func (list *frequencyMap) Frequency(password string) int {
+	return list.frequencies[password]
+}
+
+func (list *frequencyMap) FrequencyOfRank(rank int) int {
+	return list.descending[rank]
+}
+
+func (list *frequencyMap) Len() int {
+	return len(list.descending)
+}
+
+func (list *frequencyMap) Total() int {
+	return list.total
+}
+
+func (list *frequencyMap) QthProbability(q int) (float64, error) {
+	return qthProbability(q, len(list.descending), list.total, list.FrequencyOfRank)
+}
+
+// FrequencyIndex is a frequency list precomputed by BuildFrequencyIndex and memory-mapped, so that lists with millions
+// of passwords e.g. the full rockyou distribution are neither parsed nor kept on the heap. Lookups take O(log n)
+type FrequencyIndex struct {
+	data  []byte
+	count int
+	total int
+	close func() error
+}
+
+// BuildFrequencyIndex writes the frequency blacklist to an index file that can be opened with OpenFrequencyIndex. The
+// index is written to a temporary file that replaces the index once it's synced, so that a crash or a full disk never
+// leaves a truncated index
+func BuildFrequencyIndex(frequencyBlacklist map[string]int, filename string) error {
+	passwords := make([]string, 0, len(frequencyBlacklist))
+	descending := make([]int, 0, len(frequencyBlacklist))
+	total := 0
+	for password, frequency := range frequencyBlacklist {
+		passwords = append(passwords, password)
+		descending = append(descending, frequency)
+		total += frequency
+	}
+	sort.Strings(passwords)
+	sort.Sort(sort.Reverse(sort.IntSlice(descending)))
+
+	temporary := filename + ".tmp"
+	file, err := os.Create(temporary)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary)
+	defer file.Close()
+
+	// the first error of the writes is returned once the index is written
+	writer := bufio.NewWriter(file)
+	var writeErr error
+	write := func(value int) {
+		if writeErr == nil {
+			writeErr = binary.Write(writer, binary.LittleEndian, uint64(value))
+		}
+	}
+	writeString := func(value string) {
+		if writeErr == nil {
+			_, writeErr = writer.WriteString(value)
+		}
+	}
+
+	writeString(indexMagic)
+	write(len(passwords))
+	write(total)
+
+	offset := 0
+	for _, password := range passwords {
+		write(offset)
+		offset += len(password)
+	}
+	write(offset)
+
+	for _, password := range passwords {
+		write(frequencyBlacklist[password])
+	}
+
+	for _, frequency := range descending {
+		write(frequency)
+	}
+
+	for _, password := range passwords {
+		writeString(password)
+	}
+
+	if writeErr != nil {
+		return writeErr
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temporary, filename)
+}
+
+// OpenFrequencyIndex memory-maps an index written by BuildFrequencyIndex
+func OpenFrequencyIndex(filename string) (*FrequencyIndex, error) {
+	data, close, err := mapFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < indexHeaderSize || string(data[:len(indexMagic)]) != indexMagic {
+		close()
+		return nil, errors.New(filename + " is not a frequency index")
+	}
+
+	index := &FrequencyIndex{
+		data:  data,
+		count: int(binary.LittleEndian.Uint64(data[8:])),
+		total: int(binary.LittleEndian.Uint64(data[16:])),
+		close: close,
+	}
+
+	// every password takes at least its offset, frequency and rank in the index
+	if index.count < 0 || index.count > len(data)/24 || len(data) < index.passwordsOffset() || len(data) != index.passwordsOffset()+index.offset(index.count) {
+		close()
+		return nil, errors.New("frequency index " + filename + " is truncated")
+	}
+
+	return index, nil
+}
+
+// Close unmaps the index
+func (index *FrequencyIndex) Close() error {
+	return index.close()
+}
+
+// Name returns histogram, the index is the histogram model of the frequency list
+func (index *FrequencyIndex) Name() string {
+	return models.Histogram
+}
+
+// Len returns the number of passwords in the index
+func (index *FrequencyIndex) Len() int {
+	return index.count
+}
+
+// Frequency returns the frequency of the password, 0 if it isn't in the index
+func (index *FrequencyIndex) Frequency(password string) int {
+	key := []byte(password)
+
+	i := sort.Search(index.count, func(i int) bool {
+		return bytes.Compare(index.password(i), key) >= 0
+	})
+
+	if i < index.count && bytes.Equal(index.password(i), key) {
+		return index.uint64At(indexHeaderSize + (index.count+1)*8 + i*8)
+	}
+	return 0
+}
+
+// Probability returns the probability of the password in the index, 0 if it isn't in the index
+func (index *FrequencyIndex) Probability(password string) float64 {
+	if index.total == 0 {
+		return 0
+	}
+	return float64(index.Frequency(password)) / float64(index.total)
+}
+
+// QthProbability returns the probability of the qth most probable password of the index
+func (index *FrequencyIndex) QthProbability(q int) (float64, error) {
+	return qthProbability(q, index.count, index.total, index.FrequencyOfRank)
+}
+
+// FrequencyOfRank returns the frequency of the password of the given rank, from 0 for the most probable password
+func (index *FrequencyIndex) FrequencyOfRank(rank int) int {
+	return index.uint64At(indexHeaderSize + (index.count+1)*8 + index.count*8 + rank*8)
+}
+
+// Total returns the sum of the frequencies of the passwords in the index
+func (index *FrequencyIndex) Total() int {
+	return index.total
+}
+
+// password returns the ith password in byte order
+func (index *FrequencyIndex) password(i int) []byte {
+	start := index.passwordsOffset()
+	return index.data[start+index.offset(i) : start+index.offset(i+1)]
+}
+
+// offset returns the offset of the ith password from the start of the passwords
+func (index *FrequencyIndex) offset(i int) int {
+	return index.uint64At(indexHeaderSize + i*8)
+}
+
+func (index *FrequencyIndex) passwordsOffset() int {
+	return indexHeaderSize + (index.count+1)*8 + 2*index.count*8
+}
+
+func (index *FrequencyIndex) uint64At(offset int) int {
+	return int(binary.LittleEndian.Uint64(index.data[offset:]))
+}
+
+// qthProbability returns the probability of the qth most probable password given the frequency of each rank,
+// it has the same bounds as FindProbabilityOfQthPassword
+func qthProbability(q int, count int, total int, frequency func(rank int) int) (float64, error) {
+	if q >= count {
+		return 0, errors.New("q is larger than the frequency list")
+	}
+
+	if q < 1 {
+		return 0, errors.New("q must be at least 1")
+	}
+
+	if total == 0 {
+		return 0, errors.New("probability of qth password is 0")
+	}
+
+	return float64(frequency(q-1)) / float64(total), nil
+}
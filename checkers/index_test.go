@@ -0,0 +1,90 @@
+package checkers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ppartarr/tipsy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrequencyIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	frequencyBlacklist := LoadFrequencyBlacklist("../data/rockyou-1k-withcount.txt", 6)
+	file := filepath.Join(dir, "rockyou-1k.idx")
+	assert.Nil(t, BuildFrequencyIndex(frequencyBlacklist, file))
+
+	index, err := OpenFrequencyIndex(file)
+	assert.Nil(t, err)
+	defer index.Close()
+
+	// the index is the same distribution as the frequency list
+	list := NewFrequencyList(frequencyBlacklist)
+	assert.Equal(t, len(frequencyBlacklist), index.Len())
+	for password, frequency := range frequencyBlacklist {
+		assert.Equal(t, frequency, index.Frequency(password))
+		assert.Equal(t, list.Probability(password), index.Probability(password))
+	}
+	assert.Equal(t, 0.0, index.Probability("drowssap"))
+
+	for _, q := range []int{0, 1, 10, 100, len(frequencyBlacklist) - 1, len(frequencyBlacklist)} {
+		expected, expectedErr := FindProbabilityOfQthPassword(frequencyBlacklist, q)
+		probability, err := index.QthProbability(q)
+		assert.Equal(t, expected, probability)
+		assert.Equal(t, expectedErr, err)
+
+		probability, err = list.QthProbability(q)
+		assert.Equal(t, expected, probability)
+		assert.Equal(t, expectedErr, err)
+	}
+
+	// the optimal checker finds the same ball with the index
+	checker := NewService(testTypos, topCorrectors)
+	expected, err := checker.CheckOptimal("PASSWORD1", frequencyBlacklist, 10)
+	assert.Nil(t, err)
+	ball, err := checker.CheckOptimalList("PASSWORD1", index, index, 10)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, expected, ball)
+}
+
+func TestOpenFrequencyIndexInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "invalid.idx")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("password"), 0644))
+	_, err = OpenFrequencyIndex(file)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, BuildFrequencyIndex(map[string]int{"password": 10, "123456": 5}, file))
+	content, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(file, content[:len(content)-1], 0644))
+	_, err = OpenFrequencyIndex(file)
+	assert.NotNil(t, err, "a truncated index should not be opened")
+}
+
+func TestLoadFrequencyListRebuildsInvalidIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// an index truncated by a crash is newer than the frequency list, it's rebuilt rather than failing every start
+	optimalConfig := &config.OptimalChecker{File: "../data/rockyou-1k-withcount.txt", Index: filepath.Join(dir, "rockyou-1k.idx")}
+	assert.Nil(t, ioutil.WriteFile(optimalConfig.Index, []byte(indexMagic), 0644))
+
+	list, err := loadFrequencyList(optimalConfig, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, len(LoadFrequencyBlacklist(optimalConfig.File, 6)), list.Len())
+	list.(*FrequencyIndex).Close()
+
+	// the index is written to a temporary file first, which doesn't remain
+	_, err = os.Stat(optimalConfig.Index + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
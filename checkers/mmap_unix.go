@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package checkers
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile memory-maps the file read-only and returns the function to unmap it
+func mapFile(filename string) ([]byte, func() error, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// empty files can't be mapped
+	if info.Size() == 0 {
+		return []byte{}, func() error { return nil }, nil
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,15 @@
+package checkers
+
+import (
+	"io/ioutil"
+)
+
+// mapFile reads the file in memory, memory-mapped files aren't supported on windows
+func mapFile(filename string) ([]byte, func() error, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error { return nil }, nil
+}
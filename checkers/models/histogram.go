@@ -0,0 +1,35 @@
+package models
+
+// HistogramModel is the empirical distribution of a frequency list, passwords that aren't in the list have probability 0
+type HistogramModel struct {
+	Frequencies map[string]int
+	Total       int
+}
+
+// NewHistogram returns the histogram of a frequency list e.g. ./data/rockyou-1k-withcount.txt
+func NewHistogram(frequencies map[string]int) *HistogramModel {
+	total := 0
+	for _, frequency := range frequencies {
+		total += frequency
+	}
+
+	return &HistogramModel{
+		Frequencies: frequencies,
+		Total:       total,
+	}
+}
+
+// Name returns histogram
+func (model *HistogramModel) Name() string {
+	return Histogram
+}
+
+// Probability returns the frequency of the password divided by the number of passwords in the list
+func (model *HistogramModel) Probability(password string) float64 {
+	frequency, ok := model.Frequencies[password]
+	if !ok || model.Total == 0 {
+		return 0
+	}
+
+	return float64(frequency) / float64(model.Total)
+}
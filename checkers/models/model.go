@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/gob"
+	"errors"
+	"os"
+)
+
+// the password models
+const (
+	Histogram = "histogram"
+	NGram     = "ngram"
+	PCFG      = "pcfg"
+)
+
+// PasswordModel estimates the probability that a user chooses a password, it's used by the optimal checker to
+// estimate the probability that an attacker guesses a password in the ball
+type PasswordModel interface {
+	// Name returns the name of the model e.g. ngram
+	Name() string
+	// Probability returns the probability of the password
+	Probability(password string) float64
+}
+
+// Train trains the model of the given type on a distribution of passwords e.g. from ./data/rockyou-withcount.txt.
+// n is the order of the ngram model, it's ignored by the other models
+func Train(modelType string, frequencies map[string]int, n int) (PasswordModel, error) {
+	switch modelType {
+	case Histogram:
+		return NewHistogram(frequencies), nil
+	case NGram:
+		return TrainNGram(frequencies, n)
+	case PCFG:
+		return TrainPCFG(frequencies), nil
+	}
+
+	return nil, errors.New("unknown password model " + modelType + " - use one of: " + Histogram + ", " + NGram + ", " + PCFG)
+}
+
+// serialisedModel is the model written to disk, only the field of its type is set
+type serialisedModel struct {
+	Type      string
+	Histogram *HistogramModel
+	NGram     *NGramModel
+	PCFG      *PCFGModel
+}
+
+// Save writes the trained model to a file so that it's only trained once
+func Save(model PasswordModel, filename string) error {
+	serialised := serialisedModel{Type: model.Name()}
+
+	switch model := model.(type) {
+	case *HistogramModel:
+		serialised.Histogram = model
+	case *NGramModel:
+		serialised.NGram = model
+	case *PCFGModel:
+		serialised.PCFG = model
+	default:
+		return errors.New("cannot save password model " + model.Name())
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return gob.NewEncoder(file).Encode(&serialised)
+}
+
+// Load reads a model written by Save
+func Load(filename string) (PasswordModel, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	serialised := serialisedModel{}
+	err = gob.NewDecoder(file).Decode(&serialised)
+	if err != nil {
+		return nil, errors.New("could not decode password model " + filename + ": " + err.Error())
+	}
+
+	switch {
+	case serialised.Type == Histogram && serialised.Histogram != nil:
+		return serialised.Histogram, nil
+	case serialised.Type == NGram && serialised.NGram != nil:
+		return serialised.NGram, nil
+	case serialised.Type == PCFG && serialised.PCFG != nil:
+		return serialised.PCFG, nil
+	}
+
+	return nil, errors.New("unknown password model " + serialised.Type + " in " + filename)
+}
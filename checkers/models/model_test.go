@@ -0,0 +1,78 @@
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFrequencies = map[string]int{
+	"password":   50,
+	"password1":  30,
+	"123456":     40,
+	"iloveyou":   20,
+	"princess1!": 10,
+}
+
+func TestHistogram(t *testing.T) {
+	model := NewHistogram(testFrequencies)
+	assert.Equal(t, 50.0/150.0, model.Probability("password"))
+	assert.Equal(t, 0.0, model.Probability("drowssap"))
+}
+
+func TestNGram(t *testing.T) {
+	model, err := TrainNGram(testFrequencies, 3)
+	assert.Nil(t, err)
+
+	// every password has a non-zero probability, the passwords like the training passwords are more probable
+	assert.Greater(t, model.Probability("password"), model.Probability("passwore"))
+	assert.Greater(t, model.Probability("password12"), model.Probability("qzjxkvwy12"))
+	assert.Greater(t, model.Probability("qzjxkvwy12"), 0.0)
+
+	_, err = TrainNGram(testFrequencies, 0)
+	assert.NotNil(t, err)
+}
+
+func TestPCFG(t *testing.T) {
+	structure, segments, terminals := parse("princess1!")
+	assert.Equal(t, "L8D1S1", structure)
+	assert.Equal(t, []string{"L8", "D1", "S1"}, segments)
+	assert.Equal(t, []string{"princess", "1", "!"}, terminals)
+
+	model := TrainPCFG(testFrequencies)
+
+	// iloveyou1 isn't in the training passwords but its structure and segments are, with add-one smoothing
+	assert.InDelta(t, 31.0/155.0*21.0/114.0*41.0/42.0, model.Probability("iloveyou1"), 1e-12)
+
+	// an unseen string in a seen structure is less probable than a seen one, but guessable
+	assert.InDelta(t, 31.0/155.0*1.0/114.0*41.0/42.0, model.Probability("qzjxkvwy1"), 1e-12)
+	assert.Greater(t, model.Probability("iloveyou1"), model.Probability("qzjxkvwy1"))
+
+	// an unseen structure and segment
+	assert.InDelta(t, 1.0/155.0/(33.0*33.0*33.0), model.Probability("!!!"), 1e-12)
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "models")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, modelType := range []string{Histogram, NGram, PCFG} {
+		model, err := Train(modelType, testFrequencies, 2)
+		assert.Nil(t, err)
+
+		file := filepath.Join(dir, modelType+".gob")
+		assert.Nil(t, Save(model, file))
+
+		loaded, err := Load(file)
+		assert.Nil(t, err)
+		assert.Equal(t, modelType, loaded.Name())
+		assert.Equal(t, model.Probability("password12"), loaded.Probability("password12"))
+	}
+
+	_, err = Train("markov", testFrequencies, 2)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// the padding of the passwords, the start of the password is padded to fill the first context
+const (
+	startOfPassword = "\x02"
+	endOfPassword   = "\x03"
+)
+
+// NGramModel is a character Markov model of order n - 1: the probability of each character depends on the n - 1
+// characters before it. Transitions that aren't in the training passwords are smoothed with add-one (Laplace) so that
+// every password has a non-zero probability
+type NGramModel struct {
+	N int
+	// Transitions are the number of times each character, or the end of the password, follows a context
+	Transitions map[string]map[string]int
+	// Contexts are the number of transitions from each context
+	Contexts map[string]int
+	// Alphabet is the number of characters in the training passwords, plus the end of the password
+	Alphabet int
+}
+
+// TrainNGram trains an ngram model of order n e.g. 3 for trigrams
+func TrainNGram(frequencies map[string]int, n int) (*NGramModel, error) {
+	if n < 1 {
+		return nil, errors.New("the order of the ngram model must be at least 1")
+	}
+
+	model := &NGramModel{
+		N:           n,
+		Transitions: make(map[string]map[string]int),
+		Contexts:    make(map[string]int),
+	}
+	alphabet := map[string]bool{endOfPassword: true}
+
+	for password, frequency := range frequencies {
+		model.transitions(password, func(context string, next string) {
+			if model.Transitions[context] == nil {
+				model.Transitions[context] = make(map[string]int)
+			}
+			model.Transitions[context][next] += frequency
+			model.Contexts[context] += frequency
+			alphabet[next] = true
+		})
+	}
+	model.Alphabet = len(alphabet)
+
+	return model, nil
+}
+
+// Name returns ngram
+func (model *NGramModel) Name() string {
+	return NGram
+}
+
+// Probability returns the product of the probability of each character given the characters before it, and of the
+// end of the password
+func (model *NGramModel) Probability(password string) float64 {
+	probability := 1.0
+
+	model.transitions(password, func(context string, next string) {
+		// characters that aren't in the training passwords get one more symbol in the alphabet
+		probability *= float64(model.Transitions[context][next]+1) / float64(model.Contexts[context]+model.Alphabet+1)
+	})
+
+	return probability
+}
+
+// transitions calls f with every context of n - 1 characters and the character that follows it in the password
+func (model *NGramModel) transitions(password string, f func(context string, next string)) {
+	runes := []string{}
+	for i := 1; i < model.N; i++ {
+		runes = append(runes, startOfPassword)
+	}
+	for _, character := range password {
+		runes = append(runes, string(character))
+	}
+	runes = append(runes, endOfPassword)
+
+	for i := model.N - 1; i < len(runes); i++ {
+		f(strings.Join(runes[i-model.N+1:i], ""), runes[i])
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"math"
+	"strconv"
+	"unicode"
+)
+
+// PCFGModel is the probabilistic context-free grammar of Weir et al. A password is split into segments of letters (L),
+// digits (D) and symbols (S) e.g. password123! => L8 D3 S1 which is its base structure. The probability of a password is the
+// probability of its base structure times the probability of each segment e.g. P(L8 D3 S1) * P(password|L8) * P(123|D3) * P(!|S1)
+type PCFGModel struct {
+	// Structures are the number of passwords with each base structure e.g. L8D3S1
+	Structures map[string]int
+	Total      int
+	// Terminals are the number of times each string is used for a segment e.g. L8 => password
+	Terminals map[string]map[string]int
+	// Segments are the number of strings used for each segment
+	Segments map[string]int
+}
+
+// TrainPCFG trains the grammar on a distribution of passwords
+func TrainPCFG(frequencies map[string]int) *PCFGModel {
+	model := &PCFGModel{
+		Structures: make(map[string]int),
+		Terminals:  make(map[string]map[string]int),
+		Segments:   make(map[string]int),
+	}
+
+	for password, frequency := range frequencies {
+		structure, segments, terminals := parse(password)
+
+		model.Structures[structure] += frequency
+		model.Total += frequency
+
+		for i, segment := range segments {
+			if model.Terminals[segment] == nil {
+				model.Terminals[segment] = make(map[string]int)
+			}
+			model.Terminals[segment][terminals[i]] += frequency
+			model.Segments[segment] += frequency
+		}
+	}
+
+	return model
+}
+
+// Name returns pcfg
+func (model *PCFGModel) Name() string {
+	return PCFG
+}
+
+// classSizes are the number of characters of each class, used to guess the strings of segments that aren't in the
+// training passwords
+var classSizes = map[string]float64{"L": 52, "D": 10, "S": 33}
+
+// Probability returns the probability of the base structure of the password times the probability of its segments.
+// Base structures and strings that aren't in the training passwords are smoothed with add-one (Laplace) like the
+// ngram model, and the strings of a segment that isn't in the training passwords are equally likely
+func (model *PCFGModel) Probability(password string) float64 {
+	structure, segments, terminals := parse(password)
+
+	// base structures that aren't in the training passwords get one more structure
+	probability := float64(model.Structures[structure]+1) / float64(model.Total+len(model.Structures)+1)
+
+	for i, segment := range segments {
+		if model.Segments[segment] == 0 {
+			probability /= segmentSize(segment)
+			continue
+		}
+
+		// strings that aren't in the training passwords get one more string for the segment
+		probability *= float64(model.Terminals[segment][terminals[i]]+1) / float64(model.Segments[segment]+len(model.Terminals[segment])+1)
+	}
+
+	return probability
+}
+
+// segmentSize returns the number of strings of a segment e.g. 10^3 for D3
+func segmentSize(segment string) float64 {
+	length, _ := strconv.Atoi(segment[1:])
+	return math.Pow(classSizes[segment[:1]], float64(length))
+}
+
+// parse splits the password into runs of characters of the same class and returns the base structure of the password
+// e.g. L8D3S1, its segments e.g. [L8 D3 S1] and the string of each segment e.g. [password 123 !]
+func parse(password string) (string, []string, []string) {
+	structure := ""
+	segments := make([]string, 0)
+	terminals := make([]string, 0)
+
+	runes := []rune(password)
+	for start := 0; start < len(runes); {
+		class := characterClass(runes[start])
+
+		end := start + 1
+		for end < len(runes) && characterClass(runes[end]) == class {
+			end++
+		}
+
+		segment := class + strconv.Itoa(end-start)
+		structure += segment
+		segments = append(segments, segment)
+		terminals = append(terminals, string(runes[start:end]))
+
+		start = end
+	}
+
+	return structure, segments, terminals
+}
+
+// characterClass returns L for letters, D for digits and S for any other character
+func characterClass(character rune) string {
+	switch {
+	case unicode.IsLetter(character):
+		return "L"
+	case unicode.IsDigit(character):
+		return "D"
+	}
+	return "S"
+}
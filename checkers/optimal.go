@@ -2,6 +2,8 @@ package checkers
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"log"
 	"math"
 	"os"
@@ -9,52 +11,141 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/ppartarr/tipsy/checkers/models"
 	"github.com/ppartarr/tipsy/correctors"
 	"gonum.org/v1/gonum/stat/combin"
 )
 
+// Optimal is the checker that only corrects the typos that maximise the number of corrected logins while keeping the
+// probability of guessing the password in q guesses the same
+type Optimal struct {
+	service *Service
+	list    FrequencyList
+	q       int
+	model   models.PasswordModel
+}
+
+// NewOptimal initialises the optimal checker with the frequency list e.g. from ./data/rockyou-1k-withcount.txt and the
+// rank q of the most probable password an attacker can guess. The model estimates the probability of the passwords
+// in the ball, the histogram of the frequency list is used if it's nil
+func NewOptimal(service *Service, list FrequencyList, q int, model models.PasswordModel) *Optimal {
+	if model == nil {
+		model = list
+	}
+
+	return &Optimal{
+		service: service,
+		list:    list,
+		q:       q,
+		model:   model,
+	}
+}
+
+// Verify checks the submitted password and the optimal set of passwords in the ball
+func (checker *Optimal) Verify(ctx context.Context, submittedPassword string, credential *Credential) (*Result, error) {
+	return checker.service.verifyCorrected(ctx, submittedPassword, credential, func(service *Service) ([]correctors.Correction, error) {
+		return service.optimal(submittedPassword, checker.list, checker.model, checker.q)
+	})
+}
+
+// WithOptions returns a copy of the checker that corrects typos using the given options e.g. the keyboard layout
+func (checker *Optimal) WithOptions(options *correctors.Options) Checker {
+	return NewOptimal(checker.service.WithOptions(options), checker.list, checker.q, checker.model)
+}
+
 // CheckOptimal use the given distribution of passwords and a distribution of typos to decide whether to correct the typo or not
-func (checker *Checker) CheckOptimal(submittedPassword string, frequencyBlacklist map[string]int, q int) []string {
+func (checker *Service) CheckOptimal(submittedPassword string, frequencyBlacklist map[string]int, q int) ([]string, error) {
+	list := NewFrequencyList(frequencyBlacklist)
+	return checker.CheckOptimalList(submittedPassword, list, list, q)
+}
+
+// CheckOptimalWithModel is CheckOptimal with a model of the probability of the passwords e.g. an ngram model, so that the
+// passwords that aren't in the frequency blacklist but are easy to guess are accounted for
+func (checker *Service) CheckOptimalWithModel(submittedPassword string, frequencyBlacklist map[string]int, model models.PasswordModel, q int) ([]string, error) {
+	return checker.CheckOptimalList(submittedPassword, NewFrequencyList(frequencyBlacklist), model, q)
+}
+
+// CheckOptimalList is CheckOptimalWithModel with a frequency list e.g. a FrequencyIndex of the full rockyou distribution
+func (checker *Service) CheckOptimalList(submittedPassword string, list FrequencyList, model models.PasswordModel, q int) ([]string, error) {
+	corrections, err := checker.optimal(submittedPassword, list, model, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return correctors.Passwords(corrections), nil
+}
+
+func (checker *Service) optimal(submittedPassword string, list FrequencyList, model models.PasswordModel, q int) ([]correctors.Correction, error) {
+
+	ball, err := correctors.GetCorrections(submittedPassword, checker.Correctors, checker.Options)
+	if err != nil {
+		return nil, err
+	}
 
-	var ball map[string]string = correctors.GetBallWithCorrectionType(submittedPassword, checker.Correctors)
+	return checker.filterOptimal(submittedPassword, ball, list, model, q)
+}
+
+// filterOptimal returns the optimal subset of the corrections of the ball
+func (checker *Service) filterOptimal(submittedPassword string, ball []correctors.Correction, list FrequencyList, model models.PasswordModel, q int) ([]correctors.Correction, error) {
 	var ballProbability = make(map[string]float64)
+	var ballCorrections = make(map[string]correctors.Correction)
+
+	for _, correction := range ball {
+		passwordInBall := correction.Password
+		ballCorrections[passwordInBall] = correction
 
-	for passwordInBall, correctionType := range ball {
-		// probability of guessing the password in the ball from the blacklist
-		PasswordProbability := PasswordProbability(passwordInBall, frequencyBlacklist)
+		// probability of guessing the password in the ball e.g. from the blacklist
+		PasswordProbability := model.Probability(passwordInBall)
 
 		// probability that the user made the user made the typo associated to the correction e.g. swc-all
-		typoProbability := checker.CalculateTypoProbability(correctionType)
+		// or every typo in the chain for composite corrections e.g. swc-all then rm-last
+		typoProbability := checker.CalculateChainProbability(correction.Chain)
 
-		// TODO change this to make probs customisable e.g. ngram vs pcfg vs historgram vs pwmodel
-		// only add password to ball if PasswordProbability * typoProbability > 0
-		ballProbability[passwordInBall] = PasswordProbability * typoProbability
+		// only add password to ball if PasswordProbability * typoProbability > 0, the others don't add any utility
+		if PasswordProbability*typoProbability > 0 {
+			ballProbability[passwordInBall] = PasswordProbability * typoProbability
+		}
 	}
 
 	// find the optimal set of passwords in the ball such that aggregate probability of each password in the ball
 	// is lower than the probability of the qth most probable password in the blacklist
-	probabilityOfQthPassword := FindProbabilityOfQthPassword(frequencyBlacklist, q)
-	cutoff := float64(probabilityOfQthPassword) - PasswordProbability(submittedPassword, frequencyBlacklist)
+	probabilityOfQthPassword, err := list.QthProbability(q)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := float64(probabilityOfQthPassword) - model.Probability(submittedPassword)
 
 	// get the set of passwords that maximises utility subject to completeness and security
 	combinationToTry := CombinationProbability{}
 	combinationToTry = FindOptimalSubset(ballProbability, cutoff)
 
-	return combinationToTry.Passwords
+	corrections := make([]correctors.Correction, 0, len(combinationToTry.Passwords))
+	for _, password := range combinationToTry.Passwords {
+		corrections = append(corrections, ballCorrections[password])
+	}
+
+	return corrections, nil
 }
 
 // FindFrequencyOfQthPassword given the blacklist, find the probability of the qth password in the distribution
-func FindFrequencyOfQthPassword(frequencyBlacklist map[string]int, q int) int {
+func FindFrequencyOfQthPassword(frequencyBlacklist map[string]int, q int) (int, error) {
+	if q < 0 || q >= len(frequencyBlacklist) {
+		return 0, errors.New("q is larger than the frequency list")
+	}
+
 	sortedSlice := correctors.ConvertMapToSortedSlice(frequencyBlacklist)
-	return sortedSlice[q].Value
+	return sortedSlice[q].Value, nil
 }
 
 // FindProbabilityOfQthPassword given the blacklist, find the probability of the qth password in the distribution
-func FindProbabilityOfQthPassword(frequencyBlacklist map[string]int, q int) float64 {
+func FindProbabilityOfQthPassword(frequencyBlacklist map[string]int, q int) (float64, error) {
 
 	if q >= len(frequencyBlacklist) {
-		log.Fatal("q is larger than the frequency list")
-		return 0
+		return 0, errors.New("q is larger than the frequency list")
+	}
+
+	if q < 1 {
+		return 0, errors.New("q must be at least 1")
 	}
 
 	frequencies := make([]int, len(frequencyBlacklist))
@@ -69,19 +160,173 @@ func FindProbabilityOfQthPassword(frequencyBlacklist map[string]int, q int) floa
 
 	for _, frequency := range frequencyBlacklist {
 		if frequency == frequencies[len(frequencies)-q] {
-			return float64(frequency) / float64(totalNumberOfPassword)
+			return float64(frequency) / float64(totalNumberOfPassword), nil
 		}
 	}
 
-	log.Fatal("probability of qth password is 0")
-	return 0
+	return 0, errors.New("probability of qth password is 0")
 }
 
+// exactSubsetLimit is the size of the ball up to which FindOptimalSubset is exact, larger balls are solved
+// to within OptimalSubsetEpsilon of the optimal set
+const exactSubsetLimit = 20
+
+// OptimalSubsetEpsilon is the relative error of the optimal subset of large balls, their aggregate probability is at
+// least (1 - OptimalSubsetEpsilon) times that of the optimal subset
+var OptimalSubsetEpsilon = 0.01
+
 // FindOptimalSubset given the ball of a password, will solve a simple optimisation problem to find the
 // set of password such that the total aggregate probability of the set is lower than that of the
 // qth most probable password
-// returns the set with the highest utility
+// returns the set with the highest utility, and the smallest set if several sets have the same utility
 func FindOptimalSubset(ballProbability map[string]float64, cutoff float64) CombinationProbability {
+	epsilon := 0.0
+	if len(ballProbability) > exactSubsetLimit {
+		epsilon = OptimalSubsetEpsilon
+	}
+
+	return FindApproximateOptimalSubset(ballProbability, cutoff, epsilon)
+}
+
+// FindApproximateOptimalSubset finds the set of passwords with the highest aggregate probability lower than the cutoff,
+// to within a relative error epsilon e.g. 0.01 for a set at least 99% as probable as the optimal set, or 0 for the
+// optimal set. The sums of the subsets of the first i passwords are merged with the sums of the first i - 1 passwords
+// and the sums above the cutoff are dropped. When epsilon > 0, sums closer than epsilon * cutoff / 2n are trimmed so
+// there are at most 2n / epsilon sums and it takes polynomial time in n and 1 / epsilon
+func FindApproximateOptimalSubset(ballProbability map[string]float64, cutoff float64, epsilon float64) CombinationProbability {
+	passwordsInBall := make([]string, 0, len(ballProbability))
+	total := 0.0
+	for password, probability := range ballProbability {
+		// passwords more probable than the cutoff are never part of the set
+		if password != "" && probability > 0 && probability <= cutoff {
+			passwordsInBall = append(passwordsInBall, password)
+			total += probability
+		}
+	}
+
+	// most probable passwords first so that the sums are deterministic and the small sets are found first
+	sort.Slice(passwordsInBall, func(i, j int) bool {
+		if ballProbability[passwordsInBall[i]] != ballProbability[passwordsInBall[j]] {
+			return ballProbability[passwordsInBall[i]] > ballProbability[passwordsInBall[j]]
+		}
+		return passwordsInBall[i] < passwordsInBall[j]
+	})
+
+	// the whole ball is below the cutoff, otherwise the optimal set is at least half the cutoff which bounds the
+	// error of the trimmed sums
+	if len(passwordsInBall) == 0 || total <= cutoff {
+		combination := CombinationProbability{}
+		for _, password := range passwordsInBall {
+			combination.addPassword(password)
+			combination.addProbability(ballProbability[password])
+		}
+		return combination
+	}
+
+	grid := epsilon * cutoff / float64(2*len(passwordsInBall))
+
+	// the sums of the subsets seen so far, sorted by probability, with the smallest set for each probability.
+	// The sets are linked lists of passwords in nodes so that a sum shares the set of the sum it extends
+	nodes := make([]subsetNode, 0)
+	sums := []subsetSum{{last: -1, password: -1}}
+	for index, password := range passwordsInBall {
+		probability := ballProbability[password]
+
+		withPassword := make([]subsetSum, 0, len(sums))
+		for _, sum := range sums {
+			if sum.probability+probability <= cutoff {
+				withPassword = append(withPassword, subsetSum{
+					probability: sum.probability + probability,
+					size:        sum.size + 1,
+					last:        sum.last,
+					password:    index,
+				})
+			}
+		}
+
+		sums = trimSums(mergeSums(sums, withPassword), grid)
+
+		// only the sums that are kept get a node
+		for i := range sums {
+			if sums[i].password >= 0 {
+				nodes = append(nodes, subsetNode{password: sums[i].password, previous: sums[i].last})
+				sums[i].last = len(nodes) - 1
+				sums[i].password = -1
+			}
+		}
+	}
+
+	best := sums[len(sums)-1]
+	combination := CombinationProbability{Probability: best.probability}
+	for node := best.last; node >= 0; node = nodes[node].previous {
+		combination.Passwords = append([]string{passwordsInBall[nodes[node].password]}, combination.Passwords...)
+	}
+
+	return combination
+}
+
+// subsetSum is the aggregate probability of a set of passwords. The set is the node last and its previous nodes, and
+// the password at index password of the ball if it isn't -1
+type subsetSum struct {
+	probability float64
+	size        int
+	last        int
+	password    int
+}
+
+// subsetNode is a password of a set, previous is the index of the node of the previous password or -1
+type subsetNode struct {
+	password int
+	previous int
+}
+
+// mergeSums merges two lists of sums sorted by probability, keeping the smallest set of passwords of equal sums
+func mergeSums(a []subsetSum, b []subsetSum) []subsetSum {
+	merged := make([]subsetSum, 0, len(a)+len(b))
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		var next subsetSum
+		if j == len(b) || (i < len(a) && a[i].probability <= b[j].probability) {
+			next = a[i]
+			i++
+		} else {
+			next = b[j]
+			j++
+		}
+
+		last := len(merged) - 1
+		if last >= 0 && merged[last].probability == next.probability {
+			if next.size < merged[last].size {
+				merged[last] = next
+			}
+			continue
+		}
+		merged = append(merged, next)
+	}
+
+	return merged
+}
+
+// trimSums drops the sums closer than grid to the previous sum that is kept, the dropped sum is approximated by the
+// smaller one
+func trimSums(sums []subsetSum, grid float64) []subsetSum {
+	if grid == 0 {
+		return sums
+	}
+
+	trimmed := []subsetSum{sums[0]}
+	for _, sum := range sums[1:] {
+		if sum.probability > trimmed[len(trimmed)-1].probability+grid {
+			trimmed = append(trimmed, sum)
+		}
+	}
+
+	return trimmed
+}
+
+// findOptimalSubsetBruteForce tries every subset of the ball, it's the reference for FindOptimalSubset
+func findOptimalSubsetBruteForce(ballProbability map[string]float64, cutoff float64) CombinationProbability {
 	passwordsInBall := make([]string, len(ballProbability))
 	i := 0
 	for word := range ballProbability {
@@ -195,7 +440,7 @@ func generateCombinations(passwordsInBall []string) [][]string {
 }
 
 // CalculateTypoProbability calculate the probability that a correction is being used using typo frequencies from Chatterjee et al.
-func (checker *Checker) CalculateTypoProbability(correctionType string) float64 {
+func (checker *Service) CalculateTypoProbability(correctionType string) float64 {
 	// number of password fixed by the given corrector in Chatterjee et al.'s study
 	typoFixProbability := make(map[string]float64)
 
@@ -214,6 +459,20 @@ func (checker *Checker) CalculateTypoProbability(correctionType string) float64
 	return typoFixProbability[correctionType]
 }
 
+// CalculateChainProbability calculates the probability of a chain of typos as the product of the probability of each typo
+func (checker *Service) CalculateChainProbability(chain []string) float64 {
+	if len(chain) == 0 {
+		return 0
+	}
+
+	probability := 1.0
+	for _, correctionType := range chain {
+		probability *= checker.CalculateTypoProbability(correctionType)
+	}
+
+	return probability
+}
+
 // LoadFrequencyBlacklist loads a file of frequency + high-probability password e.g. ./data/rockyou-1k-withcount.txt
 func LoadFrequencyBlacklist(filename string, minPasswordLength int) map[string]int {
 	file, err := os.Open(filename)
@@ -226,15 +485,13 @@ func LoadFrequencyBlacklist(filename string, minPasswordLength int) map[string]i
 	var data map[string]int = make(map[string]int)
 
 	for scanner.Scan() {
-		line := strings.Split(strings.TrimSpace(scanner.Text()), " ")
-		// line := strings.Split(scanner.Text(), " ")
-		// log.Println(line)
+		// the frequency is padded with spaces, the password is the rest of the line and may contain whitespace
+		line := strings.SplitN(strings.TrimLeft(strings.TrimRight(scanner.Text(), "\r"), " \t"), " ", 2)
 		frequency, err := strconv.Atoi(line[0])
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		// TODO add support for whitespace passwords
 		if len(line) > 1 {
 			word := line[1]
 			if len(word) >= minPasswordLength {
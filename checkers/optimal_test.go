@@ -2,8 +2,12 @@ package checkers
 
 import (
 	"fmt"
+	"math/rand"
+	"strconv"
 	"testing"
 
+	"github.com/ppartarr/tipsy/checkers/models"
+	"github.com/ppartarr/tipsy/correctors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,9 +25,10 @@ var testFrequencyBlacklist = map[string]int{
 }
 
 func TestCheckOptimal(t *testing.T) {
-	checker := NewChecker(testTypos, topCorrectors)
+	checker := NewService(testTypos, topCorrectors)
 
-	ball := checker.CheckOptimal("password!", testFrequencyBlacklist, 5)
+	ball, err := checker.CheckOptimal("password!", testFrequencyBlacklist, 5)
+	assert.Nil(t, err)
 	if !assert.ElementsMatch(t, ball, []string{"Password!", "password"}) {
 		t.Error("ball should be the set of strings containing the output of the correctors, unless it's in the blacklist")
 	}
@@ -31,8 +36,18 @@ func TestCheckOptimal(t *testing.T) {
 	// Add more tests for optimal checker
 }
 
+func TestCheckOptimalKeyClose(t *testing.T) {
+	checker := NewService(testTypos, []string{correctors.KeyClose})
+
+	ball, err := checker.CheckOptimal("psssword", testFrequencyBlacklist, 5)
+	assert.Nil(t, err)
+	if !assert.Contains(t, ball, "password") {
+		t.Error("ball should contain the adjacent key correction weighted by the kclose typo probability")
+	}
+}
+
 func TestCalculateTypoProbability(t *testing.T) {
-	checker := NewChecker(testTypos, topCorrectors)
+	checker := NewService(testTypos, topCorrectors)
 
 	prob := checker.CalculateTypoProbability("same")
 
@@ -76,13 +91,22 @@ func TestMaxProbability(t *testing.T) {
 }
 
 func TestFindProbabilityOfQthPassword(t *testing.T) {
-	prob := FindProbabilityOfQthPassword(testFrequencyBlacklist, 3)
+	prob, err := FindProbabilityOfQthPassword(testFrequencyBlacklist, 3)
+	assert.Nil(t, err)
 	fmt.Println(prob)
 	if prob != float64(80)/float64(550) {
 		t.Error("should return the corrector prob")
 	}
 }
 
+func TestFindProbabilityOfQthPasswordOutOfRange(t *testing.T) {
+	_, err := FindProbabilityOfQthPassword(testFrequencyBlacklist, len(testFrequencyBlacklist))
+	assert.NotNil(t, err, "q larger than the frequency list should return an error")
+
+	_, err = FindProbabilityOfQthPassword(testFrequencyBlacklist, 0)
+	assert.NotNil(t, err, "q smaller than 1 should return an error")
+}
+
 func TestFindOptimalSubset(t *testing.T) {
 	ballProbability := map[string]float64{
 		"password": 0.8,
@@ -100,3 +124,96 @@ func TestFindOptimalSubset(t *testing.T) {
 		t.Error("find optimal subset should return the max <= cutoff")
 	}
 }
+
+// the solver must find the same sets as trying every subset of small balls
+func TestFindOptimalSubsetBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		ballProbability := make(map[string]float64)
+		for j := 0; j < 1+random.Intn(12); j++ {
+			ballProbability["password"+strconv.Itoa(j)] = random.Float64() / 10
+		}
+		cutoff := random.Float64() / 2
+
+		expected := findOptimalSubsetBruteForce(ballProbability, cutoff)
+		comb := FindOptimalSubset(ballProbability, cutoff)
+		assert.InDelta(t, expected.Probability, comb.Probability, 1e-12)
+		assert.ElementsMatch(t, expected.Passwords, comb.Passwords)
+	}
+
+	// sets with the same probability are broken toward the smaller set
+	comb := FindOptimalSubset(map[string]float64{"a": 0.25, "b": 0.125, "c": 0.125}, 0.25)
+	assert.Equal(t, []string{"a"}, comb.Passwords)
+}
+
+func TestFindOptimalSubsetLargeBall(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	ballProbability := make(map[string]float64)
+	total := 0.0
+	for j := 0; j < 100; j++ {
+		probability := random.Float64() / 1000
+		ballProbability["password"+strconv.Itoa(j)] = probability
+		total += probability
+	}
+
+	comb := FindOptimalSubset(ballProbability, total/2)
+	assert.LessOrEqual(t, comb.Probability, total/2)
+	assert.GreaterOrEqual(t, comb.Probability, (1-OptimalSubsetEpsilon)*total/2, "the set should be near optimal")
+
+	comb = FindOptimalSubset(ballProbability, total)
+	assert.GreaterOrEqual(t, comb.Probability, (1-OptimalSubsetEpsilon)*total)
+}
+
+func TestCheckOptimalWithModel(t *testing.T) {
+	checker := NewService(testTypos, topCorrectors)
+
+	ball, err := checker.CheckOptimal("NOMORE", testFrequencyBlacklist, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"nomore"}, ball, "the histogram gives no probability to the passwords that aren't in the list")
+
+	model, err := models.TrainNGram(testFrequencyBlacklist, 2)
+	assert.Nil(t, err)
+
+	ball, err = checker.CheckOptimalWithModel("NOMORE", testFrequencyBlacklist, model, 5)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"nomore", "nOMORE", "NOMOR"}, ball)
+}
+
+func TestCheckOptimalTranspose(t *testing.T) {
+	typos := map[string]int{"same": 90234, "swc-all": 1698, "transpose": 100}
+	checker := NewService(typos, []string{correctors.Transpose})
+
+	ball, err := checker.CheckOptimal("psasword", testFrequencyBlacklist, 5)
+	assert.Nil(t, err)
+	if !assert.Contains(t, ball, "password") {
+		t.Error("ball should contain the transposition weighted by the transpose typo probability")
+	}
+
+	if !assert.Equal(t, []string{correctors.SwitchAll, correctors.Transpose}, correctors.GetNBestCorrectors(2, typos)) {
+		t.Error("transpose should be ranked against the other correctors")
+	}
+}
+
+func TestCheckOptimalComposite(t *testing.T) {
+	checker := NewService(testTypos, topCorrectors)
+
+	ball, err := checker.CheckOptimal("PASSWORD!", testFrequencyBlacklist, 5)
+	assert.Nil(t, err)
+	assert.NotContains(t, ball, "password", "depth 1 should not chain correctors")
+
+	ball, err = checker.WithOptions(&correctors.Options{Depth: 2}).CheckOptimal("PASSWORD!", testFrequencyBlacklist, 5)
+	assert.Nil(t, err)
+	if !assert.Contains(t, ball, "password") {
+		t.Error("ball should contain the correction of a caps-lock typo with an extra last character")
+	}
+}
+
+func TestCalculateChainProbability(t *testing.T) {
+	checker := NewService(testTypos, topCorrectors)
+
+	prob := checker.CalculateChainProbability([]string{correctors.SwitchAll, correctors.RemoveLast})
+	assert.Equal(t, checker.CalculateTypoProbability(correctors.SwitchAll)*checker.CalculateTypoProbability(correctors.RemoveLast), prob)
+	assert.Equal(t, 0.0, checker.CalculateChainProbability(nil))
+}
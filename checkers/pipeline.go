@@ -0,0 +1,195 @@
+package checkers
+
+import (
+	"context"
+	"math"
+
+	"github.com/nbutton23/zxcvbn-go"
+	"github.com/ppartarr/tipsy/checkers/models"
+	"github.com/ppartarr/tipsy/correctors"
+)
+
+// Stage is a stage of a pipeline, it removes passwords from the ball e.g. the passwords in a blacklist
+type Stage interface {
+	// Name returns the name of the stage e.g. blacklist
+	Name() string
+	// Filter returns the corrections of the ball that are kept, the service is the checker of the pipeline
+	Filter(submittedPassword string, ball []correctors.Correction, service *Service) ([]correctors.Correction, error)
+}
+
+// StageResult is what a stage of a pipeline removed from the ball
+type StageResult struct {
+	Stage   string
+	Removed []string
+}
+
+// Pipeline is the checker that filters the ball with each stage in order e.g. blacklist then optimal then strength
+type Pipeline struct {
+	service *Service
+	stages  []Stage
+}
+
+// NewPipeline initialises the pipeline checker with its stages
+func NewPipeline(service *Service, stages ...Stage) *Pipeline {
+	return &Pipeline{
+		service: service,
+		stages:  stages,
+	}
+}
+
+// Verify checks the submitted password and the passwords in the ball that are kept by every stage
+func (checker *Pipeline) Verify(ctx context.Context, submittedPassword string, credential *Credential) (*Result, error) {
+	var stages []StageResult
+	result, err := checker.service.verifyCorrected(ctx, submittedPassword, credential, func(service *Service) ([]correctors.Correction, error) {
+		corrections, filtered, err := checker.filter(service, submittedPassword)
+		stages = filtered
+		return corrections, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Stages = stages
+	return result, nil
+}
+
+// WithOptions returns a copy of the checker that corrects typos using the given options e.g. the keyboard layout
+func (checker *Pipeline) WithOptions(options *correctors.Options) Checker {
+	return NewPipeline(checker.service.WithOptions(options), checker.stages...)
+}
+
+// Ball returns the passwords in the ball that are kept by every stage and what each stage removed
+func (checker *Pipeline) Ball(submittedPassword string) ([]string, []StageResult, error) {
+	corrections, stages, err := checker.filter(checker.service, submittedPassword)
+	if err != nil {
+		return nil, stages, err
+	}
+
+	return correctors.Passwords(corrections), stages, nil
+}
+
+func (checker *Pipeline) filter(service *Service, submittedPassword string) ([]correctors.Correction, []StageResult, error) {
+	ball, err := correctors.GetCorrections(submittedPassword, service.Correctors, service.Options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stages := make([]StageResult, 0, len(checker.stages))
+	for _, stage := range checker.stages {
+		filtered, err := stage.Filter(submittedPassword, ball, service)
+		if err != nil {
+			return nil, stages, err
+		}
+
+		stages = append(stages, StageResult{Stage: stage.Name(), Removed: removed(ball, filtered)})
+		ball = filtered
+	}
+
+	return ball, stages, nil
+}
+
+// removed returns the passwords of the ball that aren't in the filtered ball
+func removed(ball []correctors.Correction, filtered []correctors.Correction) []string {
+	kept := make(map[string]bool)
+	for _, correction := range filtered {
+		kept[correction.Password] = true
+	}
+
+	removed := make([]string, 0)
+	for _, correction := range ball {
+		if !kept[correction.Password] {
+			removed = append(removed, correction.Password)
+		}
+	}
+
+	return removed
+}
+
+// BlacklistStage removes the passwords in a blacklist from the ball
+type BlacklistStage struct {
+	blacklist PasswordSet
+}
+
+// NewBlacklistStage initialises the blacklist stage with a blacklist loaded in memory e.g. with LoadBlacklistFile
+func NewBlacklistStage(blacklist PasswordSet) *BlacklistStage {
+	return &BlacklistStage{blacklist: blacklist}
+}
+
+// Name returns blacklist
+func (stage *BlacklistStage) Name() string {
+	return "blacklist"
+}
+
+// Filter returns the corrections of the ball that aren't in the blacklist
+func (stage *BlacklistStage) Filter(submittedPassword string, ball []correctors.Correction, service *Service) ([]correctors.Correction, error) {
+	return filterBlacklist(ball, stage.blacklist), nil
+}
+
+// OptimalStage keeps the optimal subset of the ball, see the optimal checker
+type OptimalStage struct {
+	list  FrequencyList
+	q     int
+	model models.PasswordModel
+}
+
+// NewOptimalStage initialises the optimal stage, the histogram of the frequency list is used if the model is nil
+func NewOptimalStage(list FrequencyList, q int, model models.PasswordModel) *OptimalStage {
+	if model == nil {
+		model = list
+	}
+
+	return &OptimalStage{
+		list:  list,
+		q:     q,
+		model: model,
+	}
+}
+
+// Name returns optimal
+func (stage *OptimalStage) Name() string {
+	return "optimal"
+}
+
+// Filter returns the optimal subset of the corrections of the ball
+func (stage *OptimalStage) Filter(submittedPassword string, ball []correctors.Correction, service *Service) ([]correctors.Correction, error) {
+	return service.filterOptimal(submittedPassword, ball, stage.list, stage.model, stage.q)
+}
+
+// StrengthStage removes the weak passwords from the ball, an online attacker would try them anyway
+type StrengthStage struct {
+	minScore        int
+	minGuessesLog10 float64
+}
+
+// NewStrengthStage initialises the strength stage with the minimum zxcvbn score out of 4 and the minimum log10 of the
+// number of guesses zxcvbn estimates
+func NewStrengthStage(minScore int, minGuessesLog10 float64) *StrengthStage {
+	return &StrengthStage{
+		minScore:        minScore,
+		minGuessesLog10: minGuessesLog10,
+	}
+}
+
+// Name returns strength
+func (stage *StrengthStage) Name() string {
+	return "strength"
+}
+
+// Filter returns the corrections of the ball with both a zxcvbn score and a number of guesses above the minimums
+func (stage *StrengthStage) Filter(submittedPassword string, ball []correctors.Correction, service *Service) ([]correctors.Correction, error) {
+	strong := make([]correctors.Correction, 0, len(ball))
+	for _, correction := range ball {
+		strength := zxcvbn.PasswordStrength(correction.Password, nil)
+		if strength.Score >= stage.minScore && GuessesLog10(strength.Entropy) >= stage.minGuessesLog10 {
+			strong = append(strong, correction)
+		}
+	}
+
+	return strong, nil
+}
+
+// GuessesLog10 returns the log10 of the number of guesses of a password from its zxcvbn entropy, which is the log2 of
+// the number of guesses
+func GuessesLog10(entropy float64) float64 {
+	return entropy * math.Log10(2)
+}
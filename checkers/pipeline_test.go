@@ -0,0 +1,70 @@
+package checkers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPipelineBall(t *testing.T) {
+	checker := NewPipeline(NewService(testTypos, topCorrectors),
+		NewBlacklistStage(NewStringSet([]string{"password"})),
+		NewOptimalStage(NewFrequencyList(testFrequencyBlacklist), 5, nil),
+	)
+
+	ball, stages, err := checker.Ball("password!")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"Password!"}, ball)
+
+	// each stage reports the passwords it removed from the ball
+	assert.Equal(t, []StageResult{
+		{Stage: "blacklist", Removed: []string{"password"}},
+		{Stage: "optimal", Removed: []string{"PASSWORD!"}},
+	}, stages)
+}
+
+func TestStrengthStage(t *testing.T) {
+	checker := NewPipeline(NewService(testTypos, topCorrectors), NewStrengthStage(4, 0))
+
+	ball, stages, err := checker.Ball("password!")
+	assert.Nil(t, err)
+	assert.Empty(t, ball, "weak passwords should be removed from the ball")
+	assert.ElementsMatch(t, []string{"Password!", "PASSWORD!", "password"}, stages[0].Removed)
+
+	checker = NewPipeline(NewService(testTypos, topCorrectors), NewStrengthStage(0, 0))
+	ball, _, err = checker.Ball("password!")
+	assert.Nil(t, err)
+	assert.Len(t, ball, 3)
+}
+
+func TestStrengthStageGuesses(t *testing.T) {
+	checker := NewPipeline(NewService(testTypos, topCorrectors), NewStrengthStage(0, 0.5))
+
+	ball, stages, err := checker.Ball("password!")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"Password!", "PASSWORD!"}, ball)
+	assert.Equal(t, []string{"password"}, stages[0].Removed, "passwords with fewer guesses than the threshold should be removed from the ball")
+
+	assert.InDelta(t, 3.0103, GuessesLog10(10), 0.0001)
+}
+
+func TestPipelineVerify(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	credential := &Credential{PasswordHash: string(hash)}
+
+	checker := NewPipeline(NewService(testTypos, topCorrectors), NewBlacklistStage(NewStringSet([]string{"password"})))
+
+	result, err := checker.Verify(context.Background(), "PASSWORD", credential)
+	assert.Nil(t, err)
+	assert.False(t, result.Match, "typos of a blacklisted password should not be corrected")
+	assert.Equal(t, []string{"password"}, result.Stages[0].Removed)
+
+	checker = NewPipeline(NewService(testTypos, topCorrectors))
+	result, err = checker.Verify(context.Background(), "PASSWORD", credential)
+	assert.Nil(t, err)
+	assert.True(t, result.Match)
+	assert.Equal(t, "password", result.Password)
+}
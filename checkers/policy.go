@@ -0,0 +1,97 @@
+package checkers
+
+import (
+	"sort"
+
+	"github.com/ppartarr/tipsy/config"
+	"github.com/ppartarr/tipsy/correctors"
+)
+
+// Policy personalises the correctors of each user without typtop: it narrows the correctors of a user to the
+// correctors that corrected their successful logins, most used first
+type Policy struct {
+	minLogins     int
+	maxCorrectors int
+	minCorrectors int
+}
+
+// NewPolicy initialises the policy from the personalise config
+func NewPolicy(personalise *config.Personalise) *Policy {
+	return &Policy{
+		minLogins:     personalise.MinLogins,
+		maxCorrectors: personalise.MaxCorrectors,
+		minCorrectors: personalise.MinCorrectors,
+	}
+}
+
+// Correctors returns the correctors of a user given the number of successful logins of the user and the number of
+// logins corrected by each corrector. Until the user has logged in minLogins times every corrector of the checker is
+// used, and a user never has more than maxCorrectors correctors or a corrector that isn't one of the checker's.
+// A user always keeps the first minCorrectors correctors of the checker, otherwise a user whose logins were never
+// corrected would have no corrector and no new corrector could ever be counted. Their slots are reserved first, the
+// remaining slots go to the most used correctors, and the correctors are returned most used first
+func (policy *Policy) Correctors(checkerCorrectors []string, corrections map[string]int, logins int) []string {
+	if logins < policy.minLogins {
+		return firstCorrectors(checkerCorrectors, policy.maxCorrectors)
+	}
+
+	used := make([]string, 0)
+	for _, corrector := range checkerCorrectors {
+		if corrections[corrector] > 0 {
+			used = append(used, corrector)
+		}
+	}
+
+	// the correctors that corrected the most logins first, then in the order of the checker
+	sort.SliceStable(used, func(i, j int) bool {
+		return corrections[used[i]] > corrections[used[j]]
+	})
+
+	reserved := firstCorrectors(checkerCorrectors, policy.minCorrectors)
+	reserved = firstCorrectors(reserved, policy.maxCorrectors)
+
+	chosen := append([]string{}, reserved...)
+	for _, corrector := range used {
+		if len(chosen) >= policy.maxCorrectors {
+			break
+		}
+		if !correctors.StringInSlice(corrector, chosen) {
+			chosen = append(chosen, corrector)
+		}
+	}
+
+	userCorrectors := make([]string, 0, len(chosen))
+	for _, corrector := range used {
+		if correctors.StringInSlice(corrector, chosen) {
+			userCorrectors = append(userCorrectors, corrector)
+		}
+	}
+	for _, corrector := range reserved {
+		if !correctors.StringInSlice(corrector, used) {
+			userCorrectors = append(userCorrectors, corrector)
+		}
+	}
+
+	return userCorrectors
+}
+
+// Record adds a successful login to the number of logins corrected by each corrector in the chain of the result
+func (policy *Policy) Record(corrections map[string]int, result *Result) map[string]int {
+	if corrections == nil {
+		corrections = make(map[string]int)
+	}
+
+	for _, corrector := range result.Chain {
+		corrections[corrector]++
+	}
+
+	return corrections
+}
+
+// firstCorrectors returns a copy of the first max correctors
+func firstCorrectors(correctors []string, max int) []string {
+	if len(correctors) > max {
+		correctors = correctors[:max]
+	}
+	return append([]string{}, correctors...)
+}
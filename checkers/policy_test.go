@@ -0,0 +1,73 @@
+package checkers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ppartarr/tipsy/config"
+	"github.com/ppartarr/tipsy/correctors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy(&config.Personalise{MinLogins: 3, MaxCorrectors: 2, MinCorrectors: 1})
+
+	// every corrector is used, within the cap, until the user has logged in enough times
+	assert.Equal(t, []string{correctors.SwitchAll, correctors.RemoveLast}, policy.Correctors(topCorrectors, nil, 0))
+
+	corrections := policy.Record(nil, &Result{Match: true, Chain: []string{correctors.SwitchFirst}})
+	corrections = policy.Record(corrections, &Result{Match: true, Chain: []string{correctors.SwitchFirst}})
+	corrections = policy.Record(corrections, &Result{Match: true, Chain: []string{correctors.SwitchAll, correctors.RemoveLast}})
+	corrections = policy.Record(corrections, &Result{Match: true, Password: "password"})
+	assert.Equal(t, map[string]int{correctors.SwitchFirst: 2, correctors.SwitchAll: 1, correctors.RemoveLast: 1}, corrections)
+
+	// the most used correctors first, within the cap
+	assert.Equal(t, []string{correctors.SwitchFirst, correctors.SwitchAll}, policy.Correctors(topCorrectors, corrections, 4))
+
+	// the correctors that aren't correctors of the checker anymore are dropped
+	assert.Equal(t, []string{correctors.SwitchAll}, policy.Correctors([]string{correctors.SwitchAll}, corrections, 4))
+
+	// the first corrector of the checker is kept after the used correctors
+	corrections = policy.Record(nil, &Result{Match: true, Chain: []string{correctors.SwitchFirst}})
+	assert.Equal(t, []string{correctors.SwitchFirst, correctors.SwitchAll}, policy.Correctors(topCorrectors, corrections, 4))
+}
+
+func TestPolicyWithoutCorrections(t *testing.T) {
+	policy := NewPolicy(&config.Personalise{MinLogins: 3, MaxCorrectors: 2, MinCorrectors: 1})
+
+	// a user whose logins all matched exactly keeps the first corrector, so that a typo can still be corrected and counted
+	userCorrectors := policy.Correctors(topCorrectors, map[string]int{}, 4)
+	assert.Equal(t, []string{correctors.SwitchAll}, userCorrectors)
+
+	corrections := policy.Record(map[string]int{}, &Result{Match: true, Chain: []string{correctors.SwitchAll}})
+	assert.Equal(t, []string{correctors.SwitchAll}, policy.Correctors(topCorrectors, corrections, 5))
+}
+
+func TestVerifyPersonalised(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	checker := NewAlways(NewService(testTypos, topCorrectors))
+
+	result, err := checker.Verify(context.Background(), "PASSWORD", &Credential{PasswordHash: string(hash), Correctors: []string{correctors.SwitchFirst}})
+	assert.Nil(t, err)
+	assert.False(t, result.Match, "only the correctors of the user should be used")
+
+	result, err = checker.Verify(context.Background(), "PASSWORD", &Credential{PasswordHash: string(hash), Correctors: []string{correctors.SwitchAll, correctors.KeypressEdit}})
+	assert.Nil(t, err)
+	assert.True(t, result.Match)
+	assert.Equal(t, []string{correctors.SwitchAll}, result.Chain)
+}
+
+func TestPolicyReservesMinCorrectors(t *testing.T) {
+	policy := NewPolicy(&config.Personalise{MinLogins: 3, MaxCorrectors: 2, MinCorrectors: 1})
+
+	// the user already used as many correctors as the cap, the first corrector of the checker still has a slot
+	corrections := map[string]int{correctors.SwitchFirst: 3, correctors.RemoveLast: 2}
+	assert.Equal(t, []string{correctors.SwitchFirst, correctors.SwitchAll}, policy.Correctors(topCorrectors, corrections, 5))
+
+	// the reserved slots never go above the cap
+	policy = NewPolicy(&config.Personalise{MinLogins: 3, MaxCorrectors: 2, MinCorrectors: 3})
+	assert.Equal(t, []string{correctors.RemoveLast, correctors.SwitchAll}, policy.Correctors(topCorrectors, corrections, 5))
+}
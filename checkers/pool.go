@@ -0,0 +1,99 @@
+package checkers
+
+import (
+	"context"
+	"sync"
+)
+
+// HashPool bounds the number of password hashes checked at the same time by every login, so that a flood of logins
+// doesn't use every CPU
+type HashPool struct {
+	workers int
+	slots   chan struct{}
+}
+
+// NewHashPool initialises the pool with the number of password hashes checked at the same time
+func NewHashPool(workers int) *HashPool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &HashPool{workers: workers, slots: make(chan struct{}, workers)}
+}
+
+// checkAll checks the passwords against the hash concurrently and returns which passwords match. If stopOnFirst is
+// true, the passwords that aren't checked yet are skipped once the first password matches. Each login uses at most
+// as many goroutines as the pool has workers, whatever the size of the ball. It returns the error of the context if
+// it's cancelled before every password is checked, or the error of a check that panicked
+func (pool *HashPool) checkAll(ctx context.Context, passwords []string, hash string, stopOnFirst bool) ([]bool, error) {
+	checkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	matches := make([]bool, len(passwords))
+	indexes := make(chan int)
+
+	var (
+		wait     sync.WaitGroup
+		once     sync.Once
+		checkErr error
+	)
+
+	workers := pool.workers
+	if len(passwords) < workers {
+		workers = len(passwords)
+	}
+
+	for worker := 0; worker < workers; worker++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+
+			for index := range indexes {
+				select {
+				case pool.slots <- struct{}{}:
+				case <-checkCtx.Done():
+					continue
+				}
+
+				if checkCtx.Err() != nil {
+					<-pool.slots
+					continue
+				}
+
+				match, err := checkPasswordHash(passwords[index], hash)
+				<-pool.slots
+
+				if err != nil {
+					once.Do(func() { checkErr = err })
+					continue
+				}
+
+				matches[index] = match
+				if index == 0 && match && stopOnFirst {
+					cancel()
+				}
+			}
+		}()
+	}
+
+send:
+	for index := range passwords {
+		select {
+		case indexes <- index:
+		case <-checkCtx.Done():
+			break send
+		}
+	}
+	close(indexes)
+
+	wait.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if checkErr != nil {
+		return matches, checkErr
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,76 @@
+package checkers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ppartarr/tipsy/checkers/hashers"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPool(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	pool := NewHashPool(2)
+	matches, err := pool.checkAll(context.Background(), []string{"drowssap", "Password", "password", ""}, string(hash), false)
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{false, false, true, false}, matches)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = pool.checkAll(ctx, []string{"password"}, string(hash), false)
+	assert.Equal(t, context.Canceled, err, "abandoned logins should stop hashing")
+}
+
+func TestAlwaysVerifyPool(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	credential := &Credential{PasswordHash: string(hash)}
+
+	checker := NewAlways(NewService(testTypos, topCorrectors).WithPool(NewHashPool(2)))
+
+	result, err := checker.Verify(context.Background(), "password", credential)
+	assert.Nil(t, err)
+	assert.Equal(t, ExactMatch, result.Kind)
+
+	result, err = checker.Verify(context.Background(), "PASSWORD", credential)
+	assert.Nil(t, err)
+	assert.Equal(t, CorrectedMatch, result.Kind)
+	assert.Equal(t, "password", result.Password)
+
+	result, err = checker.Verify(context.Background(), "drowssap", credential)
+	assert.Nil(t, err)
+	assert.False(t, result.Match)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = checker.Verify(ctx, "PASSWORD", credential)
+	assert.NotNil(t, err)
+}
+
+func TestHashPoolPanic(t *testing.T) {
+	defer func() { verifyHash = hashers.Verify }()
+	verifyHash = func(password string, hash string) (bool, error) {
+		if password == "panic" {
+			panic("malformed hash")
+		}
+		return password == hash, nil
+	}
+
+	passwords := []string{"drowssap", "panic", "password"}
+	for i := 0; i < 1000; i++ {
+		passwords = append(passwords, "")
+	}
+
+	// the panic is a non-match and an error instead of a crash, and the other passwords are still checked
+	matches, err := NewHashPool(2).checkAll(context.Background(), passwords, "password", false)
+	assert.NotNil(t, err)
+	assert.False(t, matches[1])
+	assert.True(t, matches[2])
+
+	credential := &Credential{PasswordHash: "password"}
+	_, err = NewAlways(NewService(testTypos, topCorrectors)).Verify(context.Background(), "panic", credential)
+	assert.NotNil(t, err, "checking without a pool should recover too")
+}
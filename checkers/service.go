@@ -1,15 +1,134 @@
 package checkers
 
-// Checker represents a checker service...
-type Checker struct {
+import (
+	"context"
+	"crypto/rsa"
+
+	"github.com/ppartarr/tipsy/checkers/typtop"
+	"github.com/ppartarr/tipsy/correctors"
+)
+
+// Checker verifies a submitted password against the credential of a user e.g. always, blacklist, optimal or typtop
+type Checker interface {
+	// Verify checks the submitted password, and the corrections of its typos, against the credential of the user. It
+	// stops checking the password if the context is cancelled e.g. when the login request is abandoned
+	Verify(ctx context.Context, submittedPassword string, credential *Credential) (*Result, error)
+	// WithOptions returns a copy of the checker that corrects typos using the given options e.g. the keyboard layout
+	WithOptions(options *correctors.Options) Checker
+}
+
+// Credential is what is stored for a user to verify their password
+type Credential struct {
+	// PasswordHash is the hash used by the always, blacklist and optimal checkers, a PHC string or a bcrypt hash
+	PasswordHash string
+	// Correctors are the correctors of the user chosen by a Policy, every corrector of the checker is used if it's nil
+	Correctors []string
+	// State and PrivateKey are used by the typtop checker
+	State      *typtop.State
+	PrivateKey *rsa.PrivateKey
+}
+
+// MatchKind is how the submitted password matched the password of the user
+type MatchKind string
+
+const (
+	// NoMatch is a wrong password
+	NoMatch MatchKind = "none"
+	// ExactMatch is the submitted password
+	ExactMatch MatchKind = "exact"
+	// CorrectedMatch is a correction of a typo in the submitted password
+	CorrectedMatch MatchKind = "corrected"
+	// CachedMatch is the password or a typo in the typo cache of a typtop user, typtop doesn't tell which
+	CachedMatch MatchKind = "cached"
+)
+
+// Result is the outcome of verifying a password
+type Result struct {
+	// Match is true if the submitted password or one of its corrections is the user's password
+	Match bool
+	// Kind is how the submitted password matched
+	Kind MatchKind
+	// BallSize is the number of corrections checked after the submitted password, or the size of the typo cache
+	BallSize int
+	// Password is the password that matched, the submitted password or one of its corrections
+	Password string
+	// Corrector is the name of the corrector that corrected the typo, empty if the submitted password matched
+	Corrector string
+	// Chain is the list of correctors applied for composite corrections e.g. swc-all then rm-last
+	Chain []string
+	// State is the updated state of a typtop user, it must be saved after every login
+	State *typtop.State
+	// Stages are the passwords removed from the ball by each stage of a pipeline
+	Stages []StageResult
+}
+
+// Service builds the ball of a password using a distribution of typos and a list of correctors
+type Service struct {
 	TypoFrequency map[string]int
 	Correctors    []string
+	Options       *correctors.Options
+	// ConstantWork is the number of password hashes checked after the submitted password on every login, 0 to stop
+	// checking at the first match
+	ConstantWork int
+	// Pool bounds the number of password hashes checked at the same time, the hashes are checked one at a time if it's nil
+	Pool *HashPool
 }
 
-// NewChecker initialises the Checker
-func NewChecker(typoFrequency map[string]int, correctors []string) (checker *Checker) {
-	return &Checker{
+// NewService initialises the Service
+func NewService(typoFrequency map[string]int, correctors []string) (checker *Service) {
+	return &Service{
 		TypoFrequency: typoFrequency,
 		Correctors:    correctors,
 	}
 }
+
+// NewChecker initialises the Service, the struct was named Checker before the Checker interface.
+//
+// Deprecated: use NewService.
+func NewChecker(typoFrequency map[string]int, correctors []string) *Service {
+	return NewService(typoFrequency, correctors)
+}
+
+// WithOptions returns a copy of the checker that corrects typos using the given options e.g. the locale
+func (checker *Service) WithOptions(options *correctors.Options) *Service {
+	withOptions := *checker
+	withOptions.Options = checker.Options.Merge(options)
+	return &withOptions
+}
+
+// WithConstantWork returns a copy of the checker that checks the same number of password hashes on every login, the
+// ball is padded with dummy checks up to the maximum ball size
+func (checker *Service) WithConstantWork(maxBallSize int) *Service {
+	withConstantWork := *checker
+	withConstantWork.ConstantWork = maxBallSize
+	return &withConstantWork
+}
+
+// WithPool returns a copy of the checker that checks the password hashes of the ball concurrently on the pool
+func (checker *Service) WithPool(pool *HashPool) *Service {
+	withPool := *checker
+	withPool.Pool = pool
+	return &withPool
+}
+
+// forCredential returns a copy of the checker that only uses the correctors of the credential that are correctors of
+// the checker
+func (checker *Service) forCredential(credential *Credential) *Service {
+	if credential == nil || credential.Correctors == nil {
+		return checker
+	}
+
+	forCredential := *checker
+	forCredential.Correctors = make([]string, 0, len(credential.Correctors))
+	for _, corrector := range credential.Correctors {
+		if correctors.StringInSlice(corrector, checker.Correctors) {
+			forCredential.Correctors = append(forCredential.Correctors, corrector)
+		}
+	}
+	return &forCredential
+}
+
+// WithLayout returns a copy of the checker that corrects typos made on the given keyboard layout
+func (checker *Service) WithLayout(layout *correctors.Layout) *Service {
+	return checker.WithOptions(&correctors.Options{Layout: layout})
+}
@@ -0,0 +1,154 @@
+package checkers
+
+import (
+	"errors"
+	"math"
+
+	"github.com/ppartarr/tipsy/config"
+)
+
+// goodTuringCutoff is the frequency above which Good-Turing keeps the observed frequencies, the counts of large
+// frequencies are too sparse to be smoothed (Katz)
+const goodTuringCutoff = 5
+
+// smoothedList is a frequency list that gives a non-zero probability to the passwords that aren't in the list
+type smoothedList struct {
+	FrequencyList
+	// seen returns the probability of a password of the list with the given frequency
+	seen func(frequency int) float64
+	// unseen is the probability of each password that isn't in the list
+	unseen float64
+}
+
+// NewSmoothedFrequencyList returns the frequency list smoothed with one of laplace, good-turing or zipf, so that the
+// optimal checker doesn't treat the passwords that aren't in the list as free to correct.
+// laplace adds alpha to the frequency of every password, seen or not. good-turing moves the probability of the passwords
+// seen once to the passwords that aren't in the list. zipf fits the frequency of the passwords to their rank and extends
+// it to the rank after the last password. The number of passwords that aren't in the list is estimated from the number
+// of passwords seen once and twice (Chao1)
+func NewSmoothedFrequencyList(list FrequencyList, smoothing string, alpha float64) (FrequencyList, error) {
+	total := float64(list.Total())
+	if list.Len() == 0 || total == 0 {
+		return nil, errors.New("cannot smooth an empty frequency list")
+	}
+
+	counts := countOfCounts(list, goodTuringCutoff+1)
+	unseenPasswords := chao1(counts[1], counts[2])
+
+	smoothed := &smoothedList{FrequencyList: list}
+
+	switch smoothing {
+	case config.LaplaceSmoothing:
+		if alpha <= 0 {
+			return nil, errors.New("laplace smoothing alpha must be positive")
+		}
+
+		denominator := total + alpha*(float64(list.Len())+unseenPasswords)
+		smoothed.seen = func(frequency int) float64 {
+			return (float64(frequency) + alpha) / denominator
+		}
+		smoothed.unseen = alpha / denominator
+
+	case config.GoodTuringSmoothing:
+		smoothed.seen = func(frequency int) float64 {
+			// adjusted frequency r* = (r + 1) N(r + 1) / N(r)
+			if frequency < goodTuringCutoff && counts[frequency] > 0 && counts[frequency+1] > 0 {
+				return float64(frequency+1) * float64(counts[frequency+1]) / float64(counts[frequency]) / total
+			}
+			return float64(frequency) / total
+		}
+
+		// a list without passwords seen once is truncated e.g. the top 1000 passwords, the passwords that aren't in
+		// the list are at most as probable as a password seen once
+		unseenMass := 1 / total
+		if counts[1] > 0 {
+			unseenMass = float64(counts[1]) / total
+		}
+		smoothed.unseen = unseenMass / unseenPasswords
+
+	case config.ZipfSmoothing:
+		c, s := fitZipf(list)
+		smoothed.seen = func(frequency int) float64 {
+			return float64(frequency) / total
+		}
+
+		// the passwords that aren't in the list are less probable than the least probable password of the list
+		smoothed.unseen = math.Min(c*math.Pow(float64(list.Len()+1), -s), float64(list.FrequencyOfRank(list.Len()-1))) / total
+
+	default:
+		return nil, errors.New("unknown smoothing " + smoothing + " - use one of: " + config.LaplaceSmoothing + ", " + config.GoodTuringSmoothing + ", " + config.ZipfSmoothing)
+	}
+
+	return smoothed, nil
+}
+
+func (list *smoothedList) Probability(password string) float64 {
+	frequency := list.Frequency(password)
+	if frequency == 0 {
+		return list.unseen
+	}
+	return list.seen(frequency)
+}
+
+func (list *smoothedList) QthProbability(q int) (float64, error) {
+	_, err := list.FrequencyList.QthProbability(q)
+	if err != nil {
+		return 0, err
+	}
+
+	return list.seen(list.FrequencyOfRank(q - 1)), nil
+}
+
+// countOfCounts returns the number of passwords with each frequency up to max, the least probable passwords are at
+// the end of the ranks
+func countOfCounts(list FrequencyList, max int) []int {
+	counts := make([]int, max+1)
+	for rank := list.Len() - 1; rank >= 0; rank-- {
+		frequency := list.FrequencyOfRank(rank)
+		if frequency > max {
+			break
+		}
+		counts[frequency]++
+	}
+	return counts
+}
+
+// chao1 estimates the number of passwords that aren't in the list from the number of passwords seen once and twice,
+// at least one
+func chao1(once int, twice int) float64 {
+	estimate := float64(once) * float64(once-1) / 2
+	if twice > 0 {
+		estimate = float64(once) * float64(once) / float64(2*twice)
+	}
+	return math.Max(estimate, 1)
+}
+
+// fitZipf fits frequency = c * rank^-s by least squares on the logarithms, on ranks sampled at powers of 2 so that it
+// takes O(log n)
+func fitZipf(list FrequencyList) (float64, float64) {
+	ranks := make([]int, 0)
+	for rank := 1; rank < list.Len(); rank *= 2 {
+		ranks = append(ranks, rank)
+	}
+	ranks = append(ranks, list.Len())
+
+	if len(ranks) < 2 {
+		return float64(list.FrequencyOfRank(0)), 1
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for _, rank := range ranks {
+		x := math.Log(float64(rank))
+		y := math.Log(float64(list.FrequencyOfRank(rank - 1)))
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	n := float64(len(ranks))
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept := (sumY - slope*sumX) / n
+
+	return math.Exp(intercept), -slope
+}
@@ -0,0 +1,77 @@
+package checkers
+
+import (
+	"testing"
+
+	"github.com/ppartarr/tipsy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// a full distribution, with passwords seen once and twice
+var testLongTail = map[string]int{
+	"password": 100,
+	"123456":   50,
+	"qwerty":   10,
+	"dragon":   3,
+	"monkey":   2,
+	"shadow":   2,
+	"master":   1,
+	"sunshine": 1,
+	"princess": 1,
+	"football": 1,
+}
+
+func TestNewSmoothedFrequencyList(t *testing.T) {
+	list := NewFrequencyList(testLongTail)
+
+	for _, smoothing := range []string{config.LaplaceSmoothing, config.GoodTuringSmoothing, config.ZipfSmoothing} {
+		smoothed, err := NewSmoothedFrequencyList(list, smoothing, 1)
+		assert.Nil(t, err)
+
+		// unseen passwords aren't free to correct, but are less probable than the passwords in the list
+		assert.Greater(t, smoothed.Probability("drowssap"), 0.0, smoothing)
+		assert.LessOrEqual(t, smoothed.Probability("drowssap"), smoothed.Probability("football"), smoothing)
+		assert.Greater(t, smoothed.Probability("password"), smoothed.Probability("qwerty"), smoothing)
+
+		probability, err := smoothed.QthProbability(1)
+		assert.Nil(t, err)
+		assert.Equal(t, smoothed.Probability("password"), probability, smoothing)
+
+		_, err = smoothed.QthProbability(len(testLongTail))
+		assert.NotNil(t, err)
+	}
+
+	_, err := NewSmoothedFrequencyList(list, "kneser-ney", 1)
+	assert.NotNil(t, err)
+
+	_, err = NewSmoothedFrequencyList(NewFrequencyList(map[string]int{}), config.ZipfSmoothing, 1)
+	assert.NotNil(t, err)
+}
+
+func TestGoodTuring(t *testing.T) {
+	smoothed, err := NewSmoothedFrequencyList(NewFrequencyList(testLongTail), config.GoodTuringSmoothing, 0)
+	assert.Nil(t, err)
+
+	// the 4 passwords seen once have 4 / 171 of the probability, for 4 * 4 / (2 * 2) = 4 unseen passwords
+	assert.InDelta(t, 4.0/171.0/4.0, smoothed.Probability("drowssap"), 1e-12)
+	// r* = 2 * N2 / N1 = 1
+	assert.InDelta(t, 1.0/171.0, smoothed.Probability("master"), 1e-12)
+	// frequencies above the cutoff aren't smoothed
+	assert.InDelta(t, 100.0/171.0, smoothed.Probability("password"), 1e-12)
+}
+
+func TestCheckOptimalSmoothed(t *testing.T) {
+	checker := NewService(testTypos, topCorrectors)
+	list := NewFrequencyList(testFrequencyBlacklist)
+
+	ball, err := checker.CheckOptimalList("NOMORE", list, list, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"nomore"}, ball)
+
+	// the typos that aren't in the list are corrected too, with a non-zero probability
+	smoothed, err := NewSmoothedFrequencyList(list, config.ZipfSmoothing, 0)
+	assert.Nil(t, err)
+	ball, err = checker.CheckOptimalList("NOMORE", smoothed, smoothed, 5)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"nomore", "nOMORE", "NOMOR"}, ball)
+}
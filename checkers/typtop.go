@@ -0,0 +1,50 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ppartarr/tipsy/checkers/typtop"
+	"github.com/ppartarr/tipsy/correctors"
+)
+
+// TypTop is the checker that learns the typos of each user, see the typtop package
+type TypTop struct {
+	checker *typtop.Checker
+}
+
+// NewTypTop initialises the typtop checker
+func NewTypTop(checker *typtop.Checker) *TypTop {
+	return &TypTop{checker: checker}
+}
+
+// Verify checks the submitted password against the typo cache of the user, the state of the user is updated in
+// the result and must be saved even if the password doesn't match
+func (checker *TypTop) Verify(ctx context.Context, submittedPassword string, credential *Credential) (*Result, error) {
+	if credential == nil || credential.State == nil || credential.PrivateKey == nil {
+		return nil, errors.New("the credential has no typtop state")
+	}
+
+	// the typo cache is small, the login is only abandoned before it's decrypted
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	match, state, err := checker.checker.Login(credential.State, submittedPassword, credential.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Match: match, Kind: NoMatch, BallSize: len(state.TypoCache), State: state}
+	if match {
+		result.Kind = CachedMatch
+		result.Password = submittedPassword
+	}
+
+	return result, nil
+}
+
+// WithOptions returns a copy of the checker that corrects typos using the given options e.g. the keyboard layout
+func (checker *TypTop) WithOptions(options *correctors.Options) Checker {
+	return NewTypTop(checker.checker.WithOptions(options))
+}
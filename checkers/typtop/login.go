@@ -2,6 +2,7 @@ package typtop
 
 import (
 	"crypto/rsa"
+	"crypto/subtle"
 	"log"
 	mrand "math/rand"
 
@@ -11,71 +12,133 @@ import (
 )
 
 // Login checks if the typtop user's password
-func (checker *Checker) Login(state *State, submittedPassword string, privateKey *rsa.PrivateKey) (bool, *State) {
-	success := false
+func (checker *Checker) Login(state *State, submittedPassword string, privateKey *rsa.PrivateKey) (bool, *State, error) {
+	match := checker.findSlot(state.TypoCache, submittedPassword, privateKey)
+	success := match >= 0
+
+	// with constant work both the cache and the wait list are updated on every login, only the update of the outcome
+	// is kept so that the response time doesn't tell if the password matched
+	if success || checker.constantWork {
+		updated, err := checker.updateState(state, submittedPassword, match, privateKey)
+		if err != nil {
+			return false, nil, err
+		}
+		if success {
+			state = updated
+		}
+	}
 
-	// decrypt typo cache
-	for i := 0; i < len(state.TypoCache); i++ {
-		encodedPrivateKey, err := aesDecrypt(submittedPassword, state.TypoCache[i])
+	if !success || checker.constantWork {
+		// add typo to wait list, padded so that every typo takes as long to encrypt
+		typo, err := encryptTypo(&privateKey.PublicKey, submittedPassword)
+		if err != nil {
+			return false, nil, err
+		}
+		if !success {
+			state.WaitList[state.Gamma] = typo
 
-		// if the decoded & decrypted private key matches
-		if err == nil && privateKey.Equal(decodeKey(encodedPrivateKey)) {
-			log.Println("private keys match!")
-			success = true
+			// increment gamma
+			state.Gamma = state.Gamma + 1%len(state.WaitList)
+		}
+	}
 
-			// get random permutation
-			permutations := generatePermutations(checker.config.TypoCache.Length)
-			pi := permutations[mrand.Intn(len(permutations))]
+	return success, state, nil
+}
 
-			// decrypt ciphered cache state
-			log.Println("decrypting cache state")
-			cacheState := decryptCacheState(privateKey, state.CipheredCacheState)
-			log.Println("cache state")
+// findSlot returns the index of the slot of the typo cache that decrypts to the private key with the submitted
+// password, -1 if there is none. With constant work every slot is decrypted, decoded and compared, and the match is
+// recorded without branching so that the slot of the match isn't leaked
+func (checker *Checker) findSlot(typoCache [][]byte, submittedPassword string, privateKey *rsa.PrivateKey) int {
+	match := -1
+	encodedKey := encodeKey(privateKey)
+
+	for i := 0; i < len(typoCache); i++ {
+		encodedPrivateKey, err := aesDecrypt(submittedPassword, typoCache[i])
+		if err != nil && !checker.constantWork {
+			continue
+		}
 
-			// decrypt typos from the wait list
-			log.Println("decrypting wait list")
-			typos := decryptWaitList(privateKey, state.WaitList)
-			log.Println("wait list: ", typos)
+		// a slot that doesn't decrypt is decoded from the encoded key so that it takes as long, it never matches
+		decrypted := 1
+		if err != nil {
+			decrypted = 0
+			encodedPrivateKey = encodedKey
+		}
 
-			typoIndexPair := &TypoIndexPair{
-				typo:  submittedPassword,
-				index: i,
-			}
+		equal := 0
+		if privateKey.Equal(decodeKey(encodedPrivateKey)) {
+			equal = 1
+		}
 
-			// update cache
-			cacheState = checker.updateCache(pi, cacheState, typoIndexPair, typos)
+		found := decrypted & equal & subtle.ConstantTimeEq(int32(match), -1)
+		match = subtle.ConstantTimeSelect(found, i, match)
 
-			// encrypt cache state
-			log.Println("encrypting cache state")
-			encryptedCacheState := encryptCacheState(&privateKey.PublicKey, cacheState)
+		if match >= 0 && !checker.constantWork {
+			log.Println("private keys match!")
+			break
+		}
+	}
 
-			// randomize typo order in typo cache
-			newTypoCache := make([][]byte, len(state.TypoCache))
-			for index, typo := range state.TypoCache {
-				newTypoCache[pi[index]] = typo
-			}
+	return match
+}
 
-			// clear the wait list (same as init)
-			log.Println("clear the wait list")
-			initWaitList(state.WaitList, &privateKey.PublicKey)
+// updateState returns the state of the user after a successful login, the given state isn't modified
+func (checker *Checker) updateState(state *State, submittedPassword string, match int, privateKey *rsa.PrivateKey) (*State, error) {
+	// get random permutation
+	permutations := generatePermutations(checker.config.TypoCache.Length)
+	pi := permutations[mrand.Intn(len(permutations))]
+
+	// decrypt ciphered cache state
+	log.Println("decrypting cache state")
+	cacheState, err := decryptCacheState(privateKey, state.CipheredCacheState)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("cache state")
 
-			// update state
-			state.CipheredCacheState = encryptedCacheState
-			state.TypoCache = newTypoCache
+	// decrypt typos from the wait list
+	log.Println("decrypting wait list")
+	typos, err := decryptWaitList(privateKey, state.WaitList)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("wait list: ", typos)
 
-			return success, state
-		}
+	typoIndexPair := &TypoIndexPair{
+		typo:  submittedPassword,
+		index: match,
 	}
 
-	if !success {
-		// add typo to wait list
-		state.WaitList[state.Gamma] = RSAEncrypt(&privateKey.PublicKey, []byte(submittedPassword))
+	// update cache
+	cacheState = checker.updateCache(pi, cacheState, typoIndexPair, typos)
 
-		// increment gamma
-		state.Gamma = state.Gamma + 1%len(state.WaitList)
+	// encrypt cache state
+	log.Println("encrypting cache state")
+	encryptedCacheState, err := encryptCacheState(&privateKey.PublicKey, cacheState)
+	if err != nil {
+		return nil, err
 	}
 
-	return success, state
+	// randomize typo order in typo cache
+	newTypoCache := make([][]byte, len(state.TypoCache))
+	for index, typo := range state.TypoCache {
+		newTypoCache[pi[index]] = typo
+	}
+
+	// clear the wait list (same as init)
+	log.Println("clear the wait list")
+	waitList, err := initWaitList(make([][]byte, len(state.WaitList)), &privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// update state
+	updated := *state
+	updated.CipheredCacheState = encryptedCacheState
+	updated.TypoCache = newTypoCache
+	updated.WaitList = waitList
+
+	return &updated, nil
 }
 
 // GeneratePermutations given an int, will generate every permutation of set of that length
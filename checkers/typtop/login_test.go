@@ -0,0 +1,68 @@
+package typtop
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppartarr/tipsy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = &config.TypTopChecker{
+	PublicKeyEncryption:     config.PublicKeyEncryption{KeyLength: 2048},
+	PasswordBasedEncryption: config.PasswordBasedEncryption{KeyLength: 1024},
+	EditDistance:            2,
+	Zxcvbn:                  0,
+	TypoCache:               config.TypoCache{Length: 4},
+	WaitList:                config.WaitList{Length: 4},
+}
+
+func TestLogin(t *testing.T) {
+	for _, constantWork := range []bool{false, true} {
+		checker := NewChecker(testConfig, map[string]int{}).WithConstantWork(constantWork)
+		state, privateKey, err := checker.Register("password")
+		assert.Nil(t, err)
+
+		assert.Equal(t, 0, checker.findSlot(state.TypoCache, "password", privateKey))
+		assert.Equal(t, -1, checker.findSlot(state.TypoCache, "drowssap", privateKey))
+
+		match, state, err := checker.Login(state, "drowssap", privateKey)
+		assert.Nil(t, err)
+		assert.False(t, match)
+
+		match, state, err = checker.Login(state, "password", privateKey)
+		assert.Nil(t, err)
+		assert.True(t, match, "the password should match with constant work: %v", constantWork)
+
+		match, _, err = checker.Login(state, "password", privateKey)
+		assert.Nil(t, err)
+		assert.True(t, match, "the password should still match after the typo cache is permuted")
+	}
+}
+
+// a password too long for the wait list must be an error rather than stop the server
+func TestLoginLongPassword(t *testing.T) {
+	checker := NewChecker(testConfig, map[string]int{})
+	state, privateKey, err := checker.Register("password")
+	assert.Nil(t, err)
+
+	_, _, err = checker.Login(state, strings.Repeat("p", 200), privateKey)
+	assert.NotNil(t, err)
+}
+
+func TestPadTypo(t *testing.T) {
+	padded, err := padTypo([]byte("password"), 32)
+	assert.Nil(t, err)
+	assert.Len(t, padded, 32)
+	assert.Equal(t, []byte("password"), unpadTypo(padded, 32))
+
+	empty, err := padTypo([]byte(""), 32)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{}, unpadTypo(empty, 32))
+
+	_, err = padTypo([]byte(strings.Repeat("p", 31)), 32)
+	assert.NotNil(t, err)
+
+	// typos of wait lists from before the padding
+	assert.Equal(t, []byte("password"), unpadTypo([]byte("password"), 32))
+}
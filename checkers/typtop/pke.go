@@ -4,21 +4,21 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
-	"log"
+	"errors"
 )
 
-func RSAEncrypt(publicKey *rsa.PublicKey, message []byte) []byte {
+func RSAEncrypt(publicKey *rsa.PublicKey, message []byte) ([]byte, error) {
 	encryptedEpsilon, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, message, nil)
 	if err != nil {
-		log.Fatal("failed encrypt the message")
+		return nil, errors.New("failed to encrypt the message: " + err.Error())
 	}
-	return encryptedEpsilon
+	return encryptedEpsilon, nil
 }
 
-func RSADecrypt(privateKey *rsa.PrivateKey, ciphertext []byte) []byte {
+func RSADecrypt(privateKey *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
 	plaintext, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, ciphertext, nil)
 	if err != nil {
-		log.Fatal("failed to decrypt the ciphertext")
+		return nil, errors.New("failed to decrypt the ciphertext: " + err.Error())
 	}
-	return plaintext
+	return plaintext, nil
 }
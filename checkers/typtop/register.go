@@ -3,6 +3,7 @@ package typtop
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
 	"log"
 	"math/big"
 )
@@ -17,11 +18,11 @@ type State struct {
 }
 
 // Register initialises the user's state
-func (checker *Checker) Register(password string) (state *State, privateKey *rsa.PrivateKey) {
+func (checker *Checker) Register(password string) (state *State, privateKey *rsa.PrivateKey, err error) {
 	// generate key
-	privateKey, err := rsa.GenerateKey(rand.Reader, checker.config.PublicKeyEncryption.KeyLength)
+	privateKey, err = rsa.GenerateKey(rand.Reader, checker.config.PublicKeyEncryption.KeyLength)
 	if err != nil {
-		log.Printf("Failed to generate RSA key\n")
+		return nil, nil, errors.New("failed to generate RSA key: " + err.Error())
 	}
 	publicKey := &privateKey.PublicKey
 
@@ -37,7 +38,10 @@ func (checker *Checker) Register(password string) (state *State, privateKey *rsa
 
 	// fill wait list with empty string
 	log.Println("init the wait list")
-	waitList = initWaitList(waitList, publicKey)
+	waitList, err = initWaitList(waitList, publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// init cache
 	log.Println("init the cache state")
@@ -45,7 +49,10 @@ func (checker *Checker) Register(password string) (state *State, privateKey *rsa
 
 	// encrypt cache state
 	log.Println("encrypt cache state")
-	encryptedCacheState := encryptCacheState(publicKey, cacheState)
+	encryptedCacheState, err := encryptCacheState(publicKey, cacheState)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// warm up the cache
 	log.Println("warm up typo cache")
@@ -67,5 +74,5 @@ func (checker *Checker) Register(password string) (state *State, privateKey *rsa
 		Gamma:              int(gamma.Int64()),
 	}
 
-	return state, privateKey
+	return state, privateKey, nil
 }
@@ -29,7 +29,7 @@ type TypoIndexPair struct {
 	index int
 }
 
-func encryptCacheState(publicKey *rsa.PublicKey, cacheState *CacheState) []byte {
+func encryptCacheState(publicKey *rsa.PublicKey, cacheState *CacheState) ([]byte, error) {
 	encodedCacheState, err := json.Marshal(cacheState)
 	if err != nil {
 		log.Println("failed to encode the cache state into json")
@@ -37,17 +37,20 @@ func encryptCacheState(publicKey *rsa.PublicKey, cacheState *CacheState) []byte
 	return RSAEncrypt(publicKey, encodedCacheState)
 }
 
-func decryptCacheState(privateKey *rsa.PrivateKey, encryptedCacheState []byte) *CacheState {
+func decryptCacheState(privateKey *rsa.PrivateKey, encryptedCacheState []byte) (*CacheState, error) {
 	cacheState := &CacheState{}
 
-	decryptedCacheState := RSADecrypt(privateKey, encryptedCacheState)
+	decryptedCacheState, err := RSADecrypt(privateKey, encryptedCacheState)
+	if err != nil {
+		return nil, err
+	}
 
-	err := json.Unmarshal(decryptedCacheState, &cacheState)
+	err = json.Unmarshal(decryptedCacheState, &cacheState)
 	if err != nil {
 		log.Println("failed to decode the cache state from json")
 	}
 
-	return cacheState
+	return cacheState, nil
 }
 
 func addPasswordsToTypoCache(pairs []TypoIndexPair, privateKey *rsa.PrivateKey, typoCache [][]byte) [][]byte {
@@ -112,7 +115,12 @@ func (checker *Checker) initCache(password string) *CacheState {
 		// we start from 1 to avoid overwritting the submitted password in TypoCache[0]
 		for i := 1; i < checker.config.TypoCache.Length; i++ {
 			// TODO if > 10 fill 10 with best correctors and rest with random strings
-			correctedPassword := correctors.ApplyCorrectionFunction(corrections[i], password)
+			corrector, err := correctors.LookupWithOptions(corrections[i], checker.options)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			correctedPassword := corrector.Apply(password)
 			log.Println(correctedPassword)
 			typoIndexPair := TypoIndexPair{
 				typo:  correctedPassword,
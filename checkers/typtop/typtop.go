@@ -4,12 +4,15 @@ import (
 	"crypto/rsa"
 
 	"github.com/ppartarr/tipsy/config"
+	"github.com/ppartarr/tipsy/correctors"
 )
 
 // Checker represents a typtop checker service...
 type Checker struct {
 	config        *config.TypTopChecker
 	typoFrequency map[string]int
+	options       *correctors.Options
+	constantWork  bool
 }
 
 // NewChecker initialises the Checker
@@ -20,6 +23,25 @@ func NewChecker(typtopConfig *config.TypTopChecker, typoFrequency map[string]int
 	}
 }
 
+// WithOptions returns a copy of the checker that corrects typos using the given options e.g. the locale
+func (checker *Checker) WithOptions(options *correctors.Options) *Checker {
+	withOptions := *checker
+	withOptions.options = checker.options.Merge(options)
+	return &withOptions
+}
+
+// WithConstantWork returns a copy of the checker that decrypts every slot of the typo cache on every login
+func (checker *Checker) WithConstantWork(constantWork bool) *Checker {
+	withConstantWork := *checker
+	withConstantWork.constantWork = constantWork
+	return &withConstantWork
+}
+
+// WithLayout returns a copy of the checker that corrects typos made on the given keyboard layout
+func (checker *Checker) WithLayout(layout *correctors.Layout) *Checker {
+	return checker.WithOptions(&correctors.Options{Layout: layout})
+}
+
 // User represents a TypTop user
 type User struct {
 	ID            int             `json:"id"`
@@ -27,4 +49,5 @@ type User struct {
 	LoginAttempts int             `json:"loginAttempts"`
 	PrivateKey    *rsa.PrivateKey `json:"privateKey"`
 	State         *State          `json:"typtopState"`
+	Layout        string          `json:"layout,omitempty"`
 }
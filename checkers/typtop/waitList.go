@@ -1,30 +1,73 @@
 package typtop
 
 import (
-	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
-	"log"
+	"encoding/binary"
+	"errors"
 )
 
-func initWaitList(waitList [][]byte, publicKey *rsa.PublicKey) [][]byte {
+func initWaitList(waitList [][]byte, publicKey *rsa.PublicKey) ([][]byte, error) {
 	for i := 0; i < len(waitList); i++ {
-		waitList[i] = RSAEncrypt(publicKey, []byte(""))
+		typo, err := encryptTypo(publicKey, "")
+		if err != nil {
+			return nil, err
+		}
+		waitList[i] = typo
 	}
-	return waitList
+	return waitList, nil
 }
 
-func decryptWaitList(privateKey *rsa.PrivateKey, waitList [][]byte) [][]byte {
+func decryptWaitList(privateKey *rsa.PrivateKey, waitList [][]byte) ([][]byte, error) {
 	typos := make([][]byte, len(waitList))
 
 	// update wait list
 	for j := 1; j < len(waitList); j++ {
 		// public key decryption of wait list j
-		typo, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, waitList[j], nil)
+		typo, err := RSADecrypt(privateKey, waitList[j])
 		if err != nil {
-			log.Fatal("failed to decrypt wait list typo")
+			return nil, errors.New("failed to decrypt wait list typo: " + err.Error())
 		}
-		typos = append(typos, typo)
+		typos = append(typos, unpadTypo(typo, waitListSize(&privateKey.PublicKey)))
+	}
+	return typos, nil
+}
+
+// encryptTypo encrypts the typo padded to the size of the wait list, every typo takes as long to encrypt
+func encryptTypo(publicKey *rsa.PublicKey, typo string) ([]byte, error) {
+	padded, err := padTypo([]byte(typo), waitListSize(publicKey))
+	if err != nil {
+		return nil, err
+	}
+	return RSAEncrypt(publicKey, padded)
+}
+
+// waitListSize is the size of the padded typos of the wait list, the longest message RSA OAEP SHA-256 can encrypt
+func waitListSize(publicKey *rsa.PublicKey) int {
+	return publicKey.Size() - 2*sha256.Size - 2
+}
+
+// padTypo prefixes the typo with its length and pads it with zeros to the given size
+func padTypo(typo []byte, size int) ([]byte, error) {
+	if len(typo)+2 > size {
+		return nil, errors.New("the typo is too long for the wait list")
+	}
+
+	padded := make([]byte, size)
+	binary.BigEndian.PutUint16(padded, uint16(len(typo)))
+	copy(padded[2:], typo)
+	return padded, nil
+}
+
+// unpadTypo returns the typo of a padded typo, the typos of wait lists from before the padding are returned as is
+func unpadTypo(padded []byte, size int) []byte {
+	if len(padded) != size {
+		return padded
+	}
+
+	length := int(binary.BigEndian.Uint16(padded))
+	if length > size-2 {
+		return padded
 	}
-	return typos
+	return padded[2 : 2+length]
 }
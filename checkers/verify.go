@@ -0,0 +1,118 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ppartarr/tipsy/checkers/hashers"
+	"github.com/ppartarr/tipsy/correctors"
+)
+
+// verifyCorrected corrects the submitted password with the service of the user, then checks the ball
+func (checker *Service) verifyCorrected(ctx context.Context, submittedPassword string, credential *Credential, correct func(service *Service) ([]correctors.Correction, error)) (*Result, error) {
+	corrections, err := correct(checker.forCredential(credential))
+	if err != nil {
+		// passwords that can't be corrected are only checked as submitted
+		log.Println("could not correct the password: " + err.Error())
+	}
+
+	return checker.verifyBall(ctx, submittedPassword, corrections, credential)
+}
+
+// verifyBall checks the submitted password first, then the remainder of the ball against the password hash of the user
+// this way timing attacks only tell if a corrector is used. With constant work every password of the ball is checked
+// even if the submitted password matches, and the ball is padded with dummy checks up to the maximum ball size, so
+// that every login takes as long
+func (checker *Service) verifyBall(ctx context.Context, submittedPassword string, ball []correctors.Correction, credential *Credential) (*Result, error) {
+	if credential == nil || credential.PasswordHash == "" {
+		return nil, errors.New("the credential has no password hash")
+	}
+
+	passwords := make([]string, 0, 1+len(ball))
+	passwords = append(passwords, submittedPassword)
+	passwords = append(passwords, correctors.Passwords(ball)...)
+
+	// the dummy checks take as long as a check of the ball, their result is ignored
+	for i := len(ball); i < checker.ConstantWork; i++ {
+		passwords = append(passwords, "")
+	}
+
+	matches, err := checker.checkPasswords(ctx, passwords, credential.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if matches[0] {
+		return &Result{Match: true, Kind: ExactMatch, BallSize: len(ball), Password: submittedPassword}, nil
+	}
+
+	for index, correction := range ball {
+		if matches[index+1] {
+			return &Result{
+				Match:     true,
+				Kind:      CorrectedMatch,
+				BallSize:  len(ball),
+				Password:  correction.Password,
+				Corrector: correction.Corrector,
+				Chain:     correction.Chain,
+			}, nil
+		}
+	}
+
+	return &Result{Kind: NoMatch, BallSize: len(ball)}, nil
+}
+
+// checkPasswords checks the passwords against the hash on the pool of the checker, or one at a time if it has no pool.
+// The remainder of the passwords is only checked if the first password doesn't match, unless the checker does
+// constant work
+func (checker *Service) checkPasswords(ctx context.Context, passwords []string, hash string) ([]bool, error) {
+	stopOnFirst := checker.ConstantWork == 0
+
+	if checker.Pool != nil {
+		return checker.Pool.checkAll(ctx, passwords, hash, stopOnFirst)
+	}
+
+	matches := make([]bool, len(passwords))
+	for index, password := range passwords {
+		// stop hashing if the login is abandoned
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// keep checking the ball after a match so that every failed login takes as long
+		match, err := checkPasswordHash(password, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		matches[index] = match
+		if index == 0 && matches[index] && stopOnFirst {
+			break
+		}
+	}
+
+	return matches, nil
+}
+
+// verifyHash verifies the password against the hash, it's replaced in the tests
+var verifyHash = hashers.Verify
+
+// checkPasswordHash verifies that the password arguments matches the given hash, of any supported format. A hash that
+// makes the hasher panic doesn't match and returns an error, so that a single login can't crash the server
+func checkPasswordHash(password, hash string) (match bool, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			match, err = false, fmt.Errorf("the password hasher panicked: %v", recovered)
+		}
+	}()
+
+	match, err = verifyHash(password, hash)
+	if err != nil {
+		log.Println("could not verify the password hash: " + err.Error())
+		return false, nil
+	}
+	return match, nil
+}
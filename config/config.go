@@ -4,8 +4,12 @@ import (
 	"errors"
 	"io/ioutil"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ppartarr/tipsy/correctors"
+	"golang.org/x/text/language"
 	"gopkg.in/yaml.v2"
 )
 
@@ -14,11 +18,18 @@ type Server struct {
 	Smtp *SMTP `yaml:"smtp"`
 
 	Checker *Checker `yaml:"checker"`
-
-	Typos             map[string]int `yaml:"typos"`
-	Correctors        []string       `yaml:"correctors"`
-	Web               *Web           `yaml:"web"`
-	MinPasswordLength int            `yaml:"minPasswordLength"`
+	Hasher  *Hasher  `yaml:"hasher"`
+
+	Typos               map[string]int                   `yaml:"typos"`
+	Correctors          []string                         `yaml:"correctors"`
+	CorrectorParameters map[string]correctors.Parameters `yaml:"correctorParameters"`
+	Keyboard            *Keyboard                        `yaml:"keyboard"`
+	Unicode             *Unicode                         `yaml:"unicode"`
+	Ball                *Ball                            `yaml:"ball"`
+	Web                 *Web                             `yaml:"web"`
+	MinPasswordLength   int                              `yaml:"minPasswordLength"`
+	// MaxPasswordLength is the maximum length in bytes of the passwords submitted to the login and registration forms
+	MaxPasswordLength int `yaml:"maxPasswordLength"`
 }
 
 type Checker struct {
@@ -26,6 +37,89 @@ type Checker struct {
 	Blacklist *BlacklistChecker `yaml:"blacklist"`
 	Optimal   *OptimalChecker   `yaml:"optimal"`
 	TypTop    *TypTopChecker    `yaml:"typtop"`
+	// Pipeline filters the ball with each stage in order e.g. blacklist then optimal then strength
+	Pipeline []Stage `yaml:"pipeline"`
+	// Strength removes the weak passwords from the ball of the always, blacklist and optimal checkers
+	Strength *StrengthFilter `yaml:"strength"`
+	// Workers is the number of password hashes checked at the same time by every login, 0 to check them one at a time
+	Workers int `yaml:"workers"`
+	// Personalise narrows the correctors of each user to the correctors they need, with the always, blacklist and optimal checkers
+	Personalise *Personalise `yaml:"personalise"`
+}
+
+// Hasher is the config of the hash of the passwords, only one of its hashers is set
+type Hasher struct {
+	Bcrypt   *BcryptHasher   `yaml:"bcrypt"`
+	Scrypt   *ScryptHasher   `yaml:"scrypt"`
+	Argon2id *Argon2idHasher `yaml:"argon2id"`
+	PBKDF2   *PBKDF2Hasher   `yaml:"pbkdf2"`
+	// Limits are the maximum parameters of the hashes that are verified e.g. of users imported from other systems
+	Limits *HasherLimits `yaml:"limits"`
+}
+
+// HasherLimits are the maximum parameters of the hashes that are verified, Argon2idMemory is in KiB
+type HasherLimits struct {
+	Argon2idMemory      uint32 `yaml:"argon2idMemory"`
+	Argon2idIterations  uint32 `yaml:"argon2idIterations"`
+	Argon2idParallelism uint8  `yaml:"argon2idParallelism"`
+	ScryptLogN          int    `yaml:"scryptLogN"`
+	ScryptBlockSize     int    `yaml:"scryptBlockSize"`
+	ScryptParallelism   int    `yaml:"scryptParallelism"`
+	PBKDF2Iterations    int    `yaml:"pbkdf2Iterations"`
+}
+
+// BcryptHasher is the config of bcrypt, Cost is the log2 of the number of rounds
+type BcryptHasher struct {
+	Cost int `yaml:"cost"`
+}
+
+// ScryptHasher is the config of scrypt, LogN is the log2 of the CPU and memory cost
+type ScryptHasher struct {
+	LogN        int `yaml:"logN"`
+	BlockSize   int `yaml:"blockSize"`
+	Parallelism int `yaml:"parallelism"`
+	KeyLength   int `yaml:"keyLength"`
+}
+
+// Argon2idHasher is the config of argon2id, Memory is in KiB
+type Argon2idHasher struct {
+	Memory      uint32 `yaml:"memory"`
+	Iterations  uint32 `yaml:"iterations"`
+	Parallelism uint8  `yaml:"parallelism"`
+	KeyLength   uint32 `yaml:"keyLength"`
+}
+
+// PBKDF2Hasher is the config of pbkdf2, Digest is the HMAC digest, one of sha256 or sha512
+type PBKDF2Hasher struct {
+	Digest     string `yaml:"digest"`
+	Iterations int    `yaml:"iterations"`
+	KeyLength  int    `yaml:"keyLength"`
+}
+
+// Stage is a stage of the pipeline checker, only one of its filters is set
+type Stage struct {
+	Blacklist *BlacklistChecker `yaml:"blacklist"`
+	Optimal   *OptimalChecker   `yaml:"optimal"`
+	Strength  *StrengthFilter   `yaml:"strength"`
+}
+
+// StrengthFilter removes the weak passwords from the ball
+type StrengthFilter struct {
+	// Zxcvbn is the minimum zxcvbn score out of 4 of the passwords in the ball
+	Zxcvbn int `yaml:"zxcvbn"`
+	// MinGuessesLog10 is the minimum log10 of the number of guesses zxcvbn estimates for the passwords in the ball
+	MinGuessesLog10 float64 `yaml:"minGuessesLog10"`
+}
+
+// Personalise is the config for the correctors of each user
+type Personalise struct {
+	// MinLogins is the number of successful logins after which the correctors of a user are narrowed to the correctors used
+	MinLogins int `yaml:"minLogins"`
+	// MaxCorrectors is the maximum number of correctors of any user
+	MaxCorrectors int `yaml:"maxCorrectors"`
+	// MinCorrectors is the number of the first correctors of the config that every user keeps, so that a user whose
+	// logins were never corrected can still be corrected
+	MinCorrectors int `yaml:"minCorrectors"`
 }
 
 type SMTP struct {
@@ -36,6 +130,33 @@ type SMTP struct {
 	From     string `yaml:"from"`
 }
 
+// Keyboard is the config for the keyboard layouts used by the correctors
+type Keyboard struct {
+	// Layout is the default layout for users that haven't chosen one
+	Layout string `yaml:"layout"`
+	// Layouts are files containing custom layouts
+	Layouts []string `yaml:"layouts"`
+}
+
+// Unicode is the config for the unicode normalisation and case mapping of passwords
+type Unicode struct {
+	// Normalisation is applied to passwords at registration and login, one of NFC, NFKC, none
+	Normalisation string `yaml:"normalisation"`
+	// Locale is used by the case correctors e.g. tr or de
+	Locale string `yaml:"locale"`
+}
+
+// Ball is the config for the size of the ball
+type Ball struct {
+	// Depth is the maximum number of correctors chained to correct a password e.g. 2 for caps-lock then an extra last character
+	Depth int `yaml:"depth"`
+	// MaxSize is the maximum number of passwords in the ball
+	MaxSize int `yaml:"maxSize"`
+	// ConstantWork checks MaxSize passwords of the ball on every login, padding the ball with dummy checks, so that the
+	// response time doesn't tell if the password was corrected or the size of the ball
+	ConstantWork bool `yaml:"constantWork"`
+}
+
 // Web is the config for the webserver
 type Web struct {
 	Register *Register `yaml:"register"`
@@ -63,12 +184,59 @@ type Reset struct {
 // BlacklistChecker represents blacklist checker
 type BlacklistChecker struct {
 	File string `yaml:"file"`
+	// Filter is the in-memory index of the blacklist, one of set, bloom
+	Filter string `yaml:"filter"`
+	// FalsePositiveRate is the probability that the bloom filter contains a password that isn't in the blacklist
+	FalsePositiveRate float64 `yaml:"falsePositiveRate"`
+	// ReloadInterval is how often the blacklist file is checked for changes, 0 to never reload it
+	ReloadInterval time.Duration `yaml:"reloadInterval"`
 }
 
+const (
+	// SetFilter indexes the blacklist in a hash set
+	SetFilter = "set"
+	// BloomFilter indexes the blacklist in a bloom filter, for blacklists with millions of passwords
+	BloomFilter = "bloom"
+)
+
 // OptimalChecker represents the optimal checker
 type OptimalChecker struct {
 	File                    string `yaml:"file"`
 	QthMostProbablePassword int    `yaml:"qthMostProbablePassword"`
+	// Index is the precomputed binary index of the frequency list, it's built from the file if it doesn't exist, is
+	// older than the file or is invalid. Use it for frequency lists with millions of passwords
+	Index     string         `yaml:"index"`
+	Smoothing *Smoothing     `yaml:"smoothing"`
+	Model     *PasswordModel `yaml:"model"`
+}
+
+// Smoothing is the config for the probability of the passwords that aren't in the frequency list
+type Smoothing struct {
+	// Type is one of laplace, good-turing, zipf
+	Type string `yaml:"type"`
+	// Alpha is added to the frequency of every password by laplace smoothing
+	Alpha float64 `yaml:"alpha"`
+}
+
+const (
+	// LaplaceSmoothing adds alpha to the frequency of every password
+	LaplaceSmoothing = "laplace"
+	// GoodTuringSmoothing gives the probability of the passwords seen once to the passwords that aren't in the list
+	GoodTuringSmoothing = "good-turing"
+	// ZipfSmoothing extends a Zipf distribution fitted to the list to the passwords that aren't in the list
+	ZipfSmoothing = "zipf"
+)
+
+// PasswordModel is the config for the model estimating the probability of the passwords in the ball
+type PasswordModel struct {
+	// Type is one of histogram, ngram, pcfg
+	Type string `yaml:"type"`
+	// File is the trained model, it's trained from the training file and written to this file if it doesn't exist
+	File string `yaml:"file"`
+	// Training is the frequency list the model is trained on e.g. ./data/rockyou-withcount.txt, defaults to the file of the optimal checker
+	Training string `yaml:"training"`
+	// N is the order of the ngram model
+	N int `yaml:"n"`
 }
 
 // TypTopChecker represents the typtop checker
@@ -105,21 +273,26 @@ type WaitList struct {
 
 // Typos represents the distribution of typos
 type Typos struct {
-	Same            int `yaml:"same"`
-	Other           int `yaml:"other"`
-	SwcAll          int `yaml:"swc-all"`
-	Kclose          int `yaml:"kclose"`
-	KeypressEdit    int `yaml:"keypress-edit"`
-	RemoveLast      int `yaml:"rm-last"`
-	SwitchFirst     int `yaml:"swc-first"`
-	RemoveFirstChar int `yaml:"rm-firstc"`
-	SwitchLast      int `yaml:"sws-last1"`
-	Tcerror         int `yaml:"tcerror"`
-	SwitchLastN     int `yaml:"sws-lastn"`
-	Upncap          int `yaml:"upncap"`
-	N2sLast         int `yaml:"n2s-last"`
-	Cap2up          int `yaml:"cap2up"`
-	Add1Last        int `yaml:"add1-last"`
+	Same             int `yaml:"same"`
+	Other            int `yaml:"other"`
+	SwcAll           int `yaml:"swc-all"`
+	Kclose           int `yaml:"kclose"`
+	KeypressEdit     int `yaml:"keypress-edit"`
+	RemoveLast       int `yaml:"rm-last"`
+	SwitchFirst      int `yaml:"swc-first"`
+	RemoveFirstChar  int `yaml:"rm-firstc"`
+	SwitchLast       int `yaml:"sws-last1"`
+	Tcerror          int `yaml:"tcerror"`
+	SwitchLastN      int `yaml:"sws-lastn"`
+	Upncap           int `yaml:"upncap"`
+	N2sLast          int `yaml:"n2s-last"`
+	Cap2up           int `yaml:"cap2up"`
+	Add1Last         int `yaml:"add1-last"`
+	Transpose        int `yaml:"transpose"`
+	RemoveLeadingWS  int `yaml:"rm-leading-ws"`
+	RemoveTrailingWS int `yaml:"rm-trailing-ws"`
+	CollapseWS       int `yaml:"collapse-ws"`
+	WrongLayout      int `yaml:"wrong-layout"`
 }
 
 // LoadServer loads the server configuration yaml
@@ -142,8 +315,6 @@ func LoadServer(configFile string) (server *Server, err error) {
 	return server, nil
 }
 
-// TODO write function to validate correctors
-
 // Load a config interface from a file
 func load(configFile string, target interface{}) error {
 	configBytes, err := ioutil.ReadFile(configFile)
@@ -165,6 +336,46 @@ func (s *Server) IsValid() error {
 		return err
 	}
 
+	// load custom keyboard layouts and check that the default layout exists
+	err = s.validateKeyboard()
+	if err != nil {
+		return err
+	}
+
+	// check that the correctors exist and configure their parameters, after the layouts they may depend on are loaded
+	err = s.validateCorrectors()
+	if err != nil {
+		return err
+	}
+
+	// check the unicode normalisation form and the locale used by the case correctors
+	err = s.validateUnicode()
+	if err != nil {
+		return err
+	}
+
+	// check the hasher of the passwords and set its default parameters
+	err = s.validateHasher()
+	if err != nil {
+		return err
+	}
+
+	// check the depth and size of the ball
+	err = s.validateBall()
+	if err != nil {
+		return err
+	}
+
+	// check that the maximum password length is set if not set to 128
+	if s.MaxPasswordLength == 0 {
+		log.Println("maximum password length is not set, using default of 128")
+		s.MaxPasswordLength = 128
+	}
+
+	if s.MaxPasswordLength < s.MinPasswordLength {
+		return errors.New("the maximum password length must not be below the minimum password length")
+	}
+
 	// check that token validity is set if not set to 15 min
 	if s.Web.Reset.TokenValidity == 0*time.Second {
 		log.Println("token validity is not set, using default value of 15 min")
@@ -183,22 +394,489 @@ func (s *Server) IsValid() error {
 		s.Web.Login.SessionValidity = 30 * time.Minute
 	}
 
+	// validate the blacklist index
+	if s.Checker.Blacklist != nil {
+		err = validateBlacklist(s.Checker.Blacklist)
+		if err != nil {
+			return err
+		}
+	}
+
+	// validate the smoothing and the password model of the optimal checker
+	if s.Checker.Optimal != nil {
+		err = validateOptimal(s.Checker.Optimal)
+		if err != nil {
+			return err
+		}
+	}
+
+	// validate the stages of the pipeline
+	if len(s.Checker.Pipeline) > 0 {
+		err = s.validatePipeline()
+		if err != nil {
+			return err
+		}
+	}
+
+	// validate the strength filter of the always, blacklist and optimal checkers
+	if s.Checker.Strength != nil {
+		if s.Checker.TypTop != nil || len(s.Checker.Pipeline) > 0 {
+			return errors.New("strength can only be used with the always, blacklist and optimal checkers - use a strength stage in the pipeline")
+		}
+
+		err = validateStrength(s.Checker.Strength)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Checker.Workers < 0 {
+		return errors.New("the number of checker workers must not be negative")
+	}
+
+	// validate the personalised correctors
+	if s.Checker.Personalise != nil {
+		err = s.validatePersonalise()
+		if err != nil {
+			return err
+		}
+	}
+
 	// validate typtop
 	if s.Checker.TypTop != nil {
-		s.validateTypTop()
+		err = s.validateTypTop()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateBlacklist(blacklist *BlacklistChecker) error {
+	switch blacklist.Filter {
+	case "":
+		log.Println("blacklist filter is not set, using default of " + SetFilter)
+		blacklist.Filter = SetFilter
+	case SetFilter:
+	case BloomFilter:
+		if blacklist.FalsePositiveRate == 0 {
+			log.Println("bloom filter false positive rate is not set, using default of 0.001")
+			blacklist.FalsePositiveRate = 0.001
+		}
+
+		if blacklist.FalsePositiveRate <= 0 || blacklist.FalsePositiveRate >= 1 {
+			return errors.New("bloom filter false positive rate must be between 0 and 1")
+		}
+	default:
+		return errors.New("unknown blacklist filter " + blacklist.Filter + " - use one of: " + SetFilter + ", " + BloomFilter)
+	}
+
+	if blacklist.ReloadInterval < 0 {
+		return errors.New("blacklist reload interval must not be negative")
+	}
+
+	return nil
+}
+
+// validateOptimal checks the smoothing and the password model of the optimal checker
+func validateOptimal(optimal *OptimalChecker) error {
+	if optimal.Smoothing != nil {
+		err := validateSmoothing(optimal.Smoothing)
+		if err != nil {
+			return err
+		}
+	}
+
+	if optimal.Model != nil {
+		err := validatePasswordModel(optimal.Model, optimal.File)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateSmoothing(smoothing *Smoothing) error {
+	switch smoothing.Type {
+	case LaplaceSmoothing:
+		if smoothing.Alpha == 0 {
+			log.Println("laplace smoothing alpha is not set, using default of 1")
+			smoothing.Alpha = 1
+		}
+
+		if smoothing.Alpha < 0 {
+			return errors.New("laplace smoothing alpha must be positive")
+		}
+	case GoodTuringSmoothing, ZipfSmoothing:
+	default:
+		return errors.New("unknown smoothing " + smoothing.Type + " - use one of: " + LaplaceSmoothing + ", " + GoodTuringSmoothing + ", " + ZipfSmoothing)
+	}
+
+	return nil
+}
+
+func validatePasswordModel(model *PasswordModel, optimalFile string) error {
+	if model.Type != "histogram" && model.Type != "ngram" && model.Type != "pcfg" {
+		return errors.New("unknown password model " + model.Type + " - use one of: histogram, ngram, pcfg")
+	}
+
+	if model.Training == "" {
+		log.Println("password model training file is not set, using " + optimalFile)
+		model.Training = optimalFile
+	}
+
+	if model.Type == "ngram" && model.N == 0 {
+		log.Println("ngram order is not set, using default of 3")
+		model.N = 3
+	}
+
+	if model.N < 0 {
+		return errors.New("ngram order must be at least 1")
 	}
 
 	return nil
 }
 
-func (s *Server) validateTypTop() {
+func (s *Server) validatePipeline() error {
+	for index, stage := range s.Checker.Pipeline {
+		numberOfFilters := 0
+
+		if stage.Blacklist != nil {
+			numberOfFilters++
+			err := validateBlacklist(stage.Blacklist)
+			if err != nil {
+				return err
+			}
+		}
+
+		if stage.Optimal != nil {
+			numberOfFilters++
+			err := validateOptimal(stage.Optimal)
+			if err != nil {
+				return err
+			}
+		}
+
+		if stage.Strength != nil {
+			numberOfFilters++
+			err := validateStrength(stage.Strength)
+			if err != nil {
+				return err
+			}
+		}
+
+		if numberOfFilters != 1 {
+			return errors.New("stage " + strconv.Itoa(index+1) + " of the pipeline must be exactly one of: blacklist, optimal, strength")
+		}
+	}
+
+	return nil
+}
+
+func validateStrength(strength *StrengthFilter) error {
+	if strength.Zxcvbn < 0 || strength.Zxcvbn > 4 {
+		return errors.New("the zxcvbn score of the strength filter must be between 0 and 4")
+	}
+
+	if strength.MinGuessesLog10 < 0 {
+		return errors.New("the minimum log10 guesses of the strength filter must not be negative")
+	}
+
+	return nil
+}
+
+func (s *Server) validatePersonalise() error {
+	if s.Checker.TypTop != nil {
+		return errors.New("the typtop checker is already personalised, remove personalise from the checker config")
+	}
+
+	if s.Checker.Personalise.MinLogins == 0 {
+		log.Println("personalise minimum logins is not set, using default of 10")
+		s.Checker.Personalise.MinLogins = 10
+	}
+
+	if s.Checker.Personalise.MaxCorrectors == 0 {
+		log.Println("personalise maximum correctors is not set, using the number of correctors")
+		s.Checker.Personalise.MaxCorrectors = len(s.Correctors)
+	}
+
+	if s.Checker.Personalise.MinCorrectors == 0 {
+		log.Println("personalise minimum correctors is not set, using default of 1")
+		s.Checker.Personalise.MinCorrectors = 1
+	}
+
+	if s.Checker.Personalise.MinLogins < 0 || s.Checker.Personalise.MaxCorrectors < 0 || s.Checker.Personalise.MinCorrectors < 0 {
+		return errors.New("personalise minimum logins, minimum correctors and maximum correctors must be positive")
+	}
+
+	return nil
+}
+
+func (s *Server) validateTypTop() error {
 	if s.Checker.TypTop.PublicKeyEncryption.KeyLength == 0 {
-		log.Fatal("please set your pke key length")
+		return errors.New("please set your pke key length")
 	}
 
 	if s.Checker.TypTop.PasswordBasedEncryption.KeyLength == 0 {
-		log.Fatal("please set your pbe key length")
+		return errors.New("please set your pbe key length")
+	}
+
+	// the typos of the wait list are prefixed with their length on 2 bytes and encrypted with RSA OAEP SHA-256, which
+	// can't encrypt more than the key size minus 66 bytes
+	if s.MaxPasswordLength > s.Checker.TypTop.PublicKeyEncryption.KeyLength/8-68 {
+		return errors.New("the maximum password length is too long for the typtop pke key length")
+	}
+
+	return nil
+}
+
+func (s *Server) validateCorrectors() error {
+	for _, corrector := range s.Correctors {
+		if !correctors.IsRegistered(corrector) {
+			return errors.New("unknown corrector " + corrector + " - use one of: " + strings.Join(correctors.Registered(), ", "))
+		}
+	}
+
+	return correctors.Configure(s.CorrectorParameters)
+}
+
+func (s *Server) validateKeyboard() error {
+	if s.Keyboard == nil {
+		log.Println("keyboard layout is not set, using default of " + correctors.US)
+		s.Keyboard = &Keyboard{}
+	}
+
+	if s.Keyboard.Layout == "" {
+		s.Keyboard.Layout = correctors.US
+	}
+
+	for _, file := range s.Keyboard.Layouts {
+		layout, err := correctors.LoadLayout(file)
+		if err != nil {
+			return err
+		}
+
+		err = correctors.RegisterLayout(layout)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := correctors.GetLayout(s.Keyboard.Layout)
+	if err != nil {
+		return errors.New("unknown keyboard layout " + s.Keyboard.Layout + " - use one of: " + strings.Join(correctors.Layouts(), ", "))
+	}
+
+	return nil
+}
+
+func (s *Server) validateUnicode() error {
+	if s.Unicode == nil {
+		log.Println("unicode normalisation is not set, using default of " + correctors.NFC)
+		s.Unicode = &Unicode{Normalisation: correctors.NFC}
+	}
+
+	if s.Unicode.Normalisation == "" {
+		s.Unicode.Normalisation = correctors.NFC
+	}
+
+	_, err := correctors.Normalise("", s.Unicode.Normalisation)
+	if err != nil {
+		return err
+	}
+
+	if s.Unicode.Locale != "" {
+		_, err = language.Parse(s.Unicode.Locale)
+		if err != nil {
+			return errors.New("unknown locale " + s.Unicode.Locale + ": " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) validateHasher() error {
+	if s.Hasher == nil {
+		log.Println("hasher is not set, using default of bcrypt")
+		s.Hasher = &Hasher{Bcrypt: &BcryptHasher{}}
 	}
+
+	numberOfHashers := 0
+
+	if s.Hasher.Bcrypt != nil {
+		numberOfHashers++
+		if s.Hasher.Bcrypt.Cost == 0 {
+			s.Hasher.Bcrypt.Cost = 14
+		}
+		if s.Hasher.Bcrypt.Cost < 4 || s.Hasher.Bcrypt.Cost > 31 {
+			return errors.New("bcrypt cost must be between 4 and 31")
+		}
+	}
+
+	if s.Hasher.Scrypt != nil {
+		numberOfHashers++
+		scrypt := s.Hasher.Scrypt
+		if scrypt.LogN == 0 {
+			log.Println("scrypt logN is not set, using default of 15")
+			scrypt.LogN = 15
+		}
+		if scrypt.BlockSize == 0 {
+			scrypt.BlockSize = 8
+		}
+		if scrypt.Parallelism == 0 {
+			scrypt.Parallelism = 1
+		}
+		if scrypt.KeyLength == 0 {
+			scrypt.KeyLength = 32
+		}
+		if scrypt.LogN < 10 || scrypt.LogN > 30 || scrypt.BlockSize < 0 || scrypt.Parallelism < 0 || scrypt.KeyLength < 16 {
+			return errors.New("scrypt logN must be between 10 and 30, its key length at least 16, and its other parameters positive")
+		}
+	}
+
+	if s.Hasher.Argon2id != nil {
+		numberOfHashers++
+		argon2id := s.Hasher.Argon2id
+		if argon2id.Memory == 0 {
+			log.Println("argon2id memory is not set, using default of 64MiB")
+			argon2id.Memory = 64 * 1024
+		}
+		if argon2id.Iterations == 0 {
+			argon2id.Iterations = 3
+		}
+		if argon2id.Parallelism == 0 {
+			argon2id.Parallelism = 4
+		}
+		if argon2id.KeyLength == 0 {
+			argon2id.KeyLength = 32
+		}
+		if argon2id.Memory < 1024 || argon2id.KeyLength < 16 {
+			return errors.New("argon2id memory must be at least 1024KiB and its key length at least 16")
+		}
+	}
+
+	if s.Hasher.PBKDF2 != nil {
+		numberOfHashers++
+		pbkdf2 := s.Hasher.PBKDF2
+		if pbkdf2.Digest == "" {
+			pbkdf2.Digest = "sha256"
+		}
+		if pbkdf2.Digest != "sha256" && pbkdf2.Digest != "sha512" {
+			return errors.New("unknown pbkdf2 digest " + pbkdf2.Digest + " - use one of: sha256, sha512")
+		}
+		if pbkdf2.Iterations == 0 {
+			log.Println("pbkdf2 iterations is not set, using default of 310000")
+			pbkdf2.Iterations = 310000
+		}
+		if pbkdf2.KeyLength == 0 {
+			pbkdf2.KeyLength = 32
+		}
+		if pbkdf2.Iterations < 1000 || pbkdf2.KeyLength < 16 {
+			return errors.New("pbkdf2 iterations must be at least 1000 and its key length at least 16")
+		}
+	}
+
+	if numberOfHashers != 1 {
+		return errors.New("the hasher must be exactly one of: bcrypt, scrypt, argon2id, pbkdf2")
+	}
+
+	return s.validateHasherLimits()
+}
+
+func (s *Server) validateHasherLimits() error {
+	if s.Hasher.Limits == nil {
+		s.Hasher.Limits = &HasherLimits{}
+	}
+
+	limits := s.Hasher.Limits
+	if limits.Argon2idMemory == 0 {
+		limits.Argon2idMemory = 256 * 1024
+	}
+	if limits.Argon2idIterations == 0 {
+		limits.Argon2idIterations = 10
+	}
+	if limits.Argon2idParallelism == 0 {
+		limits.Argon2idParallelism = 16
+	}
+	if limits.ScryptLogN == 0 {
+		limits.ScryptLogN = 20
+	}
+	if limits.ScryptBlockSize == 0 {
+		limits.ScryptBlockSize = 16
+	}
+	if limits.ScryptParallelism == 0 {
+		limits.ScryptParallelism = 16
+	}
+	if limits.PBKDF2Iterations == 0 {
+		limits.PBKDF2Iterations = 10000000
+	}
+
+	// the hashes of the hasher must be verifiable
+	argon2id := s.Hasher.Argon2id
+	if argon2id != nil && (argon2id.Memory > limits.Argon2idMemory || argon2id.Iterations > limits.Argon2idIterations || argon2id.Parallelism > limits.Argon2idParallelism) {
+		return errors.New("the argon2id parameters must not be above the hasher limits")
+	}
+
+	scrypt := s.Hasher.Scrypt
+	if scrypt != nil && (scrypt.LogN > limits.ScryptLogN || scrypt.BlockSize > limits.ScryptBlockSize || scrypt.Parallelism > limits.ScryptParallelism) {
+		return errors.New("the scrypt parameters must not be above the hasher limits")
+	}
+
+	if s.Hasher.PBKDF2 != nil && s.Hasher.PBKDF2.Iterations > limits.PBKDF2Iterations {
+		return errors.New("the pbkdf2 iterations must not be above the hasher limits")
+	}
+
+	return nil
+}
+
+func (s *Server) validateBall() error {
+	if s.Ball == nil {
+		log.Println("ball depth is not set, using default of 1")
+		s.Ball = &Ball{}
+	}
+
+	if s.Ball.Depth == 0 {
+		s.Ball.Depth = 1
+	}
+
+	if s.Ball.Depth < 0 {
+		return errors.New("ball depth must be at least 1")
+	}
+
+	// chained corrections grow the ball exponentially with the depth, and constant work pads the ball to its max size
+	if s.Ball.MaxSize == 0 && (s.Ball.Depth > 1 || s.Ball.ConstantWork) {
+		log.Println("ball max size is not set, using default of 20")
+		s.Ball.MaxSize = 20
+	}
+
+	if s.Ball.MaxSize < 0 {
+		return errors.New("ball max size must be positive")
+	}
+
+	return nil
+}
+
+// CorrectorOptions returns the options shared by every user to correct their passwords
+func (s *Server) CorrectorOptions() *correctors.Options {
+	options := &correctors.Options{Locale: s.Unicode.Tag()}
+
+	if s.Ball != nil {
+		options.Depth = s.Ball.Depth
+		options.MaxBallSize = s.Ball.MaxSize
+	}
+
+	return options
+}
+
+// Tag returns the locale used by the case correctors, language.Und if it isn't set
+func (u *Unicode) Tag() language.Tag {
+	if u == nil || u.Locale == "" {
+		return language.Und
+	}
+
+	return language.Make(u.Locale)
 }
 
 func (s *Server) validateNumberOfCheckers() error {
@@ -220,8 +898,12 @@ func (s *Server) validateNumberOfCheckers() error {
 		numberOfCheckers++
 	}
 
+	if len(s.Checker.Pipeline) > 0 {
+		numberOfCheckers++
+	}
+
 	if numberOfCheckers != 1 {
-		return errors.New("more than one checker is defined in config - use only one of: always, blacklist, optimal")
+		return errors.New("more than one checker is defined in config - use only one of: always, blacklist, optimal, typtop, pipeline")
 	}
 
 	return nil
@@ -1,10 +1,11 @@
 package correctors
 
 import (
-	"log"
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"golang.org/x/text/language"
 )
 
 // Same is the identity function
@@ -15,154 +16,218 @@ func Same(password string) string {
 // SwitchCaseFirstLetter switches the case of the first letter in the string to upper case
 // swc-first
 func SwitchCaseFirstLetter(password string) string {
-	// we avoid toTitle in case the password contains a space
-	for index, value := range password {
-		if unicode.IsLower(value) {
-			return string(unicode.ToUpper(value)) + password[index+1:]
-		}
-		return string(unicode.ToLower(value)) + password[index+1:]
-	}
-
-	// TODO should probably raise error here is password length is 0
-	return ""
+	return switchCaseFirstLetter(password, language.Und)
 }
 
 // SwitchCaseAll switches the case of all the letters in the string
 // swc-all
 func SwitchCaseAll(password string) string {
-	newPassword := ""
-	for _, value := range password {
-		if unicode.IsLower(value) {
-			newPassword = newPassword + string(unicode.ToUpper(value))
-		} else if unicode.IsUpper(value) {
-			newPassword = newPassword + string(unicode.ToLower(value))
-		} else {
-			newPassword = newPassword + string(value)
-		}
-	}
-	return newPassword
+	return switchCaseAll(password, language.Und)
 }
 
 // RemoveLastChar removes the last character from the string
 // rm-last
 func RemoveLastChar(password string) string {
-	lastCharRune, size := utf8.DecodeLastRuneInString(password)
-	if lastCharRune == utf8.RuneError && (size == 0 || size == 1) {
-		log.Fatal("Unable to decode the size of the last rune")
-		size = 0
-	}
+	// an empty password stays empty and an invalid last byte is removed on its own
+	_, size := utf8.DecodeLastRuneInString(password)
 	return password[:len(password)-size]
 }
 
 // RemoveFirstChar removes the first character from the string
 // rm-first
 func RemoveFirstChar(password string) string {
-	firstCharRune, size := utf8.DecodeRuneInString(password)
-	if firstCharRune == utf8.RuneError && (size == 0 || size == 1) {
-		log.Fatal("Unable to decode the size of the first rune")
-		size = 0
-	}
+	// an empty password stays empty and an invalid first byte is removed on its own
+	_, size := utf8.DecodeRuneInString(password)
 	return password[size:]
 }
 
 // CapitalToUpper returns the password with every letter capitalised. For when users press shift-key instead of caps-lock
 // cap2up
 func CapitalToUpper(password string) string {
-	if strings.Title(password) == password {
-		return strings.ToUpper(password)
-	}
-	return password
+	return capitalToUpper(password, language.Und)
 }
 
 // UpperToCapital returns the password with every letter capitalised. For when users press caps-lock instead of shift
 // up2cap
 func UpperToCapital(password string) string {
-	if strings.ToUpper(password) == password {
-		return strings.Title(strings.ToLower(password))
-	}
-	return password
+	return upperToCapital(password, language.Und)
 }
 
-// ConvertLastNumberToSymbol converts the last number to a symbol - TODO should depend on keyboard layout
+// ConvertLastNumberToSymbol converts the last number to a symbol on the US keyboard layout
 // n2s-last
 func ConvertLastNumberToSymbol(password string) string {
+	return convertLastNumberToSymbol(password, USQwerty)
+}
+
+func convertLastNumberToSymbol(password string, layout *Layout) string {
 	lastCharRune, size := utf8.DecodeLastRuneInString(password)
+	// the password is returned as is if it's empty or doesn't end with a valid character
 	if lastCharRune == utf8.RuneError && (size == 0 || size == 1) {
-		log.Fatal("Unable to decode the size of the last rune")
-		size = 0
+		return password
 	}
 	if unicode.IsDigit(lastCharRune) {
-		return password[:len(password)-size] + shiftSwitchMap[string(lastCharRune)]
+		if symbol, ok := layout.Shift(lastCharRune); ok {
+			return password[:len(password)-size] + string(symbol)
+		}
 	}
 	return password
 }
 
-// SwitchShiftLastCharacter changes the last character according to the appropriate shift modifier
+// SwitchShiftLastCharacter changes the last character according to the shift modifier of the US keyboard layout
 // sws-last1
 func SwitchShiftLastCharacter(password string) string {
+	return switchShiftLastCharacter(password, USQwerty)
+}
+
+func switchShiftLastCharacter(password string, layout *Layout) string {
 	lastCharRune, size := utf8.DecodeLastRuneInString(password)
+	// the password is returned as is if it's empty or doesn't end with a valid character
 	if lastCharRune == utf8.RuneError && (size == 0 || size == 1) {
-		log.Fatal("Unable to decode the size of the last rune")
-		size = 0
-	}
-	if unicode.IsDigit(lastCharRune) || unicode.IsSymbol(lastCharRune) || unicode.IsPunct(lastCharRune) {
-		return password[:len(password)-size] + shiftSwitchMap[string(lastCharRune)]
-	} else if unicode.IsLetter(lastCharRune) {
-		if unicode.IsUpper(lastCharRune) {
-			return password[:len(password)-size] + string(unicode.ToLower(lastCharRune))
-		} else if unicode.IsLower(lastCharRune) {
-			return password[:len(password)-size] + string(unicode.ToUpper(lastCharRune))
-		}
+		return password
 	}
-	return password
+	shifted, _ := layout.Shift(lastCharRune)
+	return password[:len(password)-size] + string(shifted)
 }
 
-// SwitchShiftLastNCharacters changes the last n characters according to the appropriate shift modifier
+// SwitchShiftLastNCharacters changes the last n characters according to the shift modifier of the US keyboard layout
 // sws-lastn
 func SwitchShiftLastNCharacters(password string, n int) string {
-	temp := password
-	for i := 1; i <= n; i++ {
-		// TODO improve this, it's very hacky...
-		temp = SwitchShiftLastCharacter(temp[:len(temp)-(n-i)]) + password[len(password)-(n-i):]
+	return switchShiftLastNCharacters(password, n, USQwerty)
+}
+
+func switchShiftLastNCharacters(password string, n int, layout *Layout) string {
+	runes := []rune(password)
+	for i := len(runes) - n; i < len(runes); i++ {
+		if i >= 0 {
+			runes[i], _ = layout.Shift(runes[i])
+		}
 	}
 
-	return temp
+	return string(runes)
 }
 
-// InverseRemoveLast appends every rune to the password
+// InverseRemoveLast appends every rune of the US keyboard layout and a space to the password
 func InverseRemoveLast(password string) []string {
-	edits := make([]string, 1)
+	return inverseRemoveLast(password, USQwerty)
+}
 
-	for _, letter := range LetterRunes {
-		// add every rune in every index
+func inverseRemoveLast(password string, layout *Layout) []string {
+	edits := make([]string, 0, len(layout.Runes())+1)
+
+	for _, letter := range layout.Runes() {
+		// add every rune at the end
 		edits = append(edits, password+string(letter))
 	}
+	edits = append(edits, password+" ")
 
-	return edits
+	return exactInverse(password, edits, RemoveLastChar)
 }
 
-// InverseRemoveFirst prepends every rune to the password
+// InverseRemoveFirst prepends every rune of the US keyboard layout and a space to the password
 func InverseRemoveFirst(password string) []string {
-	edits := make([]string, 1)
+	return inverseRemoveFirst(password, USQwerty)
+}
 
-	for _, letter := range LetterRunes {
-		// add every rune in every index
+func inverseRemoveFirst(password string, layout *Layout) []string {
+	edits := make([]string, 0, len(layout.Runes())+1)
+
+	for _, letter := range layout.Runes() {
+		// add every rune at the start
 		edits = append(edits, string(letter)+password)
 	}
+	edits = append(edits, " "+password)
 
-	return edits
+	return exactInverse(password, edits, RemoveFirstChar)
 }
 
-// ConvertLastNumberToSymbol converts the last number to a symbol - TODO should depend on keyboard layout
+// inverseSwitchShiftLastCharacter returns the passwords whose last character is typed with the opposite shift modifier
+func inverseSwitchShiftLastCharacter(password string, layout *Layout) []string {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return []string{}
+	}
+
+	typos := make([]string, 0)
+	for _, candidate := range shiftCandidates(runes[len(runes)-1], layout) {
+		typos = append(typos, string(runes[:len(runes)-1])+string(candidate))
+	}
+
+	return exactInverse(password, typos, func(typo string) string {
+		return switchShiftLastCharacter(typo, layout)
+	})
+}
+
+// inverseSwitchShiftLastNCharacters returns the passwords whose last n characters are typed with the opposite shift modifier
+func inverseSwitchShiftLastNCharacters(password string, n int, layout *Layout) []string {
+	runes := []rune(password)
+	// shorter passwords aren't corrected
+	if len(runes) < n {
+		return []string{}
+	}
+
+	typos := []string{string(runes[:len(runes)-n])}
+	for _, character := range runes[len(runes)-n:] {
+		next := make([]string, 0, len(typos))
+		for _, typo := range typos {
+			for _, candidate := range shiftCandidates(character, layout) {
+				next = append(next, typo+string(candidate))
+			}
+		}
+		typos = next
+	}
+
+	return exactInverse(password, typos, func(typo string) string {
+		return switchShiftLastNCharacters(typo, n, layout)
+	})
+}
+
+// shiftCandidates returns the runes that are the given rune once the shift modifier is switched
+func shiftCandidates(character rune, layout *Layout) []rune {
+	shifted, _ := layout.Shift(character)
+	candidates := []rune{shifted, unicode.ToLower(character), unicode.ToUpper(character)}
+
+	for _, candidate := range layout.Runes() {
+		if switched, _ := layout.Shift(candidate); switched == character {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	return uniqueRunes(candidates)
+}
+
+// inverseConvertLastNumberToSymbol returns the passwords whose last number is the symbol at the end of the password
+func inverseConvertLastNumberToSymbol(password string, layout *Layout) []string {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return []string{}
+	}
+
+	typos := make([]string, 0)
+	for _, candidate := range shiftCandidates(runes[len(runes)-1], layout) {
+		if unicode.IsDigit(candidate) {
+			typos = append(typos, string(runes[:len(runes)-1])+string(candidate))
+		}
+	}
+
+	return exactInverse(password, typos, func(typo string) string {
+		return convertLastNumberToSymbol(typo, layout)
+	})
+}
+
+// ConvertLastSymbolToNumber converts the last symbol to a number on the US keyboard layout
 // s2n-last
 func ConvertLastSymbolToNumber(password string) string {
+	return convertLastSymbolToNumber(password, USQwerty)
+}
+
+func convertLastSymbolToNumber(password string, layout *Layout) string {
 	lastCharRune, size := utf8.DecodeLastRuneInString(password)
+	// the password is returned as is if it's empty or doesn't end with a valid character
 	if lastCharRune == utf8.RuneError && (size == 0 || size == 1) {
-		log.Fatal("Unable to decode the size of the last rune")
-		size = 0
+		return password
 	}
-	if unicode.IsSymbol(lastCharRune) {
-		return password[:len(password)-size] + shiftSwitchMap[string(lastCharRune)]
+	if number, ok := layout.Shift(lastCharRune); ok && unicode.IsDigit(number) {
+		return password[:len(password)-size] + string(number)
 	}
 	return password
 }
@@ -172,3 +237,11 @@ func ConvertLastSymbolToNumber(password string) string {
 func AppendOne(password string) string {
 	return password + "1"
 }
+
+// inverseAppendOne returns the password without its last 1
+func inverseAppendOne(password string) []string {
+	if !strings.HasSuffix(password, "1") {
+		return []string{}
+	}
+	return []string{strings.TrimSuffix(password, "1")}
+}
@@ -139,3 +139,27 @@ func TestAppendOne(t *testing.T) {
 		t.Errorf("should add 1 to the end of the string")
 	}
 }
+
+func TestEdgeCases(t *testing.T) {
+	for _, password := range []string{"", "\xff", "password\xff"} {
+		if RemoveLastChar(password) != password[:len(password)-min(1, len(password))] {
+			t.Errorf("should remove the last byte of an empty or malformed password")
+		}
+
+		if RemoveFirstChar(password) != password[min(1, len(password)):] {
+			t.Errorf("should remove the first byte of an empty or malformed password")
+		}
+
+		if ConvertLastNumberToSymbol(password) != password {
+			t.Errorf("should not convert a malformed last character")
+		}
+
+		if SwitchShiftLastCharacter(password) != password {
+			t.Errorf("should not switch a malformed last character")
+		}
+
+		if ConvertLastSymbolToNumber(password) != password {
+			t.Errorf("should not convert a malformed last character")
+		}
+	}
+}
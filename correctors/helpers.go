@@ -1,8 +1,12 @@
 package correctors
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"sort"
+	"strings"
+	"unicode/utf8"
 )
 
 // Corrector constants
@@ -13,91 +17,40 @@ const (
 	RemoveFirst        = "rm-first"
 	SwitchLast         = "sws-last1"
 	SwitchLastN        = "sws-lastn"
+	KeyClose           = "kclose"
+	KeypressEdit       = "keypress-edit"
+	Transpose          = "transpose"
+	FullWidthHalfWidth = "fw2hw"
+	RemoveLeadingWS    = "rm-leading-ws"
+	RemoveTrailingWS   = "rm-trailing-ws"
+	CollapseWS         = "collapse-ws"
+	WrongLayout        = "wrong-layout"
 	UpperNCapital      = "upncap"
 	NumberToSymbolLast = "n2s-last"
 	Capital2Upper      = "cap2up"
 	AddOneLast         = "add1-last"
 )
 
-var allCorrectors = []string{
-	SwitchAll,
-	RemoveLast,
-	SwitchFirst,
-	RemoveFirst,
-	SwitchLast,
-	SwitchLastN,
-	UpperNCapital,
-	NumberToSymbolLast,
-	Capital2Upper,
-	AddOneLast,
-}
-
 // ApplyCorrectionFunction applies the appropriate corrector function given it's config name
 func ApplyCorrectionFunction(corrector string, password string) string {
-	// log.Println(corrector)
-	// log.Println(password)
-
-	switch corrector {
-	case SwitchAll:
-		return SwitchCaseAll(password)
-	case RemoveLast:
-		return RemoveLastChar(password)
-	case SwitchFirst:
-		return SwitchCaseFirstLetter(password)
-	case RemoveFirst:
-		return RemoveFirstChar(password)
-	case SwitchLast:
-		return SwitchShiftLastCharacter(password)
-	case SwitchLastN:
-		return SwitchShiftLastNCharacters(password, 2)
-	case UpperNCapital:
-		return UpperToCapital(password)
-	case NumberToSymbolLast:
-		return ConvertLastNumberToSymbol(password)
-	case Capital2Upper:
-		return CapitalToUpper(password)
-	case AddOneLast:
-		return AppendOne(password)
-	}
-
-	log.Fatal("corrector unknown:", corrector)
-	return password
+	c, err := Lookup(corrector)
+	if err != nil {
+		log.Println(err)
+		return password
+	}
+
+	return c.Apply(password)
 }
 
-// ApplyInverseCorrectionFunction applies the appropriate corrector function given it's config name
+// ApplyInverseCorrectionFunction returns every password that the corrector given by it's config name corrects to the password
 func ApplyInverseCorrectionFunction(corrector string, password string) []string {
-	inverse := make([]string, 1)
-
-	switch corrector {
-	case SwitchAll:
-		inverse = append(inverse, SwitchCaseAll(password))
-	case RemoveLast:
-		edits := InverseRemoveLast(password)
-		for _, edit := range edits {
-			inverse = append(inverse, edit)
-		}
-	case SwitchFirst:
-		inverse = append(inverse, SwitchCaseFirstLetter(password))
-	case RemoveFirst:
-		edits := InverseRemoveFirst(password)
-		for _, edit := range edits {
-			inverse = append(inverse, edit)
-		}
-	case SwitchLast:
-		inverse = append(inverse, SwitchShiftLastCharacter(password))
-	case SwitchLastN:
-		inverse = append(inverse, SwitchShiftLastNCharacters(password, 2))
-	case UpperNCapital:
-		inverse = append(inverse, UpperToCapital(password))
-	case NumberToSymbolLast:
-		inverse = append(inverse, ConvertLastSymbolToNumber(password))
-	case Capital2Upper:
-		inverse = append(inverse, CapitalToUpper(password))
-	case AddOneLast:
-		inverse = append(inverse, RemoveLastChar(password))
-	}
-
-	return inverse
+	c, err := Lookup(corrector)
+	if err != nil {
+		log.Println(err)
+		return []string{}
+	}
+
+	return c.Inverse(password)
 }
 
 // KeyValue reporesents a map as a slice
@@ -109,19 +62,14 @@ type KeyValue struct {
 // GetNBestCorrectors returns the n best correctors in order, determined by the typo frequency
 func GetNBestCorrectors(n int, typoFrequency map[string]int) []string {
 
-	// there are only 10 correctors implemented
-	if n > 10 {
-		log.Fatal("there are only 10 correctors")
-	}
-
 	nBestCorrectors := make([]string, 0)
 
 	ss := ConvertMapToSortedSlice(typoFrequency)
 
 	// add corrector to slice
 	for i := 0; i < len(typoFrequency); i++ {
-		// some typos aren't correction functions e.g. keypress-edit, tcerror, other
-		if StringInSlice(ss[i].Key, allCorrectors) && len(nBestCorrectors) < n {
+		// some typos aren't correction functions e.g. tcerror, other
+		if IsRegistered(ss[i].Key) && len(nBestCorrectors) < n {
 			nBestCorrectors = append(nBestCorrectors, ss[i].Key)
 		}
 	}
@@ -145,31 +93,149 @@ func ConvertMapToSortedSlice(in map[string]int) []KeyValue {
 	return ss
 }
 
-// GetBall returns the passwords in the ball given a slice of correctors
-func GetBall(password string, correctors []string) []string {
-	ball := make([]string, len(correctors))
-	for index, corrector := range correctors {
-		correctedPassword := ApplyCorrectionFunction(corrector, password)
-		if correctedPassword != password {
-			ball[index] = correctedPassword
+// Correction is a password in the ball and the name of the corrector that produced it
+type Correction struct {
+	Password string
+	// Corrector is the name of the corrector, or of every corrector in the chain joined with + e.g. swc-all+rm-last
+	Corrector string
+	// Chain are the correctors applied one after the other to the submitted password
+	Chain []string
+}
+
+// ChainSeparator joins the names of the correctors in a composite correction
+const ChainSeparator = "+"
+
+// Errors returned for passwords that can't be corrected
+var (
+	ErrEmptyPassword   = errors.New("password is empty")
+	ErrInvalidPassword = errors.New("password is not valid UTF-8")
+)
+
+// ValidatePassword returns an error if the password can't be corrected e.g. if it's empty or malformed
+func ValidatePassword(password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+
+	if !utf8.ValidString(password) {
+		return ErrInvalidPassword
+	}
+
+	return nil
+}
+
+// GetCorrections returns the ball of the password, in the order of the given correctors and without duplicates.
+// If options.Depth is larger than 1 the correctors are chained e.g. caps-lock then an extra last character,
+// the corrections with fewer steps come first and the ball is cut at options.MaxBallSize.
+// It returns an error if the password is empty or malformed, the corrections that fail are skipped
+func GetCorrections(password string, correctors []string, options *Options) ([]Correction, error) {
+	err := ValidatePassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, maxBallSize := 1, 0
+	if options != nil {
+		if options.Depth > 1 {
+			depth = options.Depth
+		}
+		maxBallSize = options.MaxBallSize
+	}
+
+	lookedUp := make([]Corrector, 0, len(correctors))
+	for _, name := range correctors {
+		corrector, err := LookupWithOptions(name, options)
+		if err != nil {
+			log.Println(err)
+			continue
 		}
+		lookedUp = append(lookedUp, corrector)
 	}
-	ball = DeleteEmpty(ball)
-	return ball
+
+	corrections := make([]Correction, 0, len(correctors))
+	// the submitted password and empty strings are never part of the ball
+	seen := map[string]bool{password: true, "": true}
+	// the passwords corrected at the previous depth, the submitted password at depth 1
+	previous := []Correction{{Password: password}}
+
+	for step := 0; step < depth && len(previous) > 0; step++ {
+		next := make([]Correction, 0)
+
+		for _, parent := range previous {
+			for _, corrector := range lookedUp {
+				for _, correctedPassword := range applyAll(corrector, parent.Password) {
+					if seen[correctedPassword] {
+						continue
+					}
+
+					if maxBallSize > 0 && len(corrections) == maxBallSize {
+						return corrections, nil
+					}
+
+					seen[correctedPassword] = true
+					chain := append(append(make([]string, 0, len(parent.Chain)+1), parent.Chain...), corrector.Name())
+					correction := Correction{correctedPassword, strings.Join(chain, ChainSeparator), chain}
+					corrections = append(corrections, correction)
+					next = append(next, correction)
+				}
+			}
+		}
+
+		previous = next
+	}
+
+	return corrections, nil
+}
+
+// applyAll returns every correction of the password made by the corrector.
+// A corrector that panics e.g. a third-party corrector on an edge case doesn't correct the password
+func applyAll(corrector Corrector, password string) (corrections []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("corrector " + corrector.Name() + " failed: " + fmt.Sprint(r))
+			corrections = []string{}
+		}
+	}()
+
+	if multiCorrector, ok := corrector.(MultiCorrector); ok {
+		return multiCorrector.ApplyAll(password)
+	}
+	return []string{corrector.Apply(password)}
+}
+
+// Passwords returns the passwords in a slice of corrections
+func Passwords(corrections []Correction) []string {
+	passwords := make([]string, len(corrections))
+	for index, correction := range corrections {
+		passwords[index] = correction.Password
+	}
+	return passwords
+}
+
+// GetBall returns the passwords in the ball given a slice of correctors
+func GetBall(password string, correctors []string) ([]string, error) {
+	corrections, err := GetCorrections(password, correctors, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteEmpty(Passwords(corrections)), nil
 }
 
 // GetBallWithCorrectionType returns the ball with the correction type string
-func GetBallWithCorrectionType(password string, correctors []string) map[string]string {
+func GetBallWithCorrectionType(password string, correctors []string) (map[string]string, error) {
 	var ballWithCorrectorName = make(map[string]string)
 
-	for _, corrector := range correctors {
-		correctedPassword := ApplyCorrectionFunction(corrector, password)
-		if correctedPassword != password {
-			ballWithCorrectorName[correctedPassword] = corrector
-		}
+	corrections, err := GetCorrections(password, correctors, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, correction := range corrections {
+		ballWithCorrectorName[correction.Password] = correction.Corrector
 	}
 
-	return ballWithCorrectorName
+	return ballWithCorrectorName, nil
 }
 
 // DeleteEmpty remove all empty strings from a slice
@@ -194,51 +260,5 @@ func StringInSlice(s string, list []string) bool {
 	return success
 }
 
-// Keyboard layout dependent
-var shiftSwitchMap = map[string]string{
-	"`":  "~",
-	"1":  "!",
-	"2":  "@",
-	"3":  "#",
-	"4":  "$",
-	"5":  "%",
-	"6":  "^",
-	"7":  "&",
-	"8":  "*",
-	"9":  "(",
-	"0":  ")",
-	"-":  "_",
-	"=":  "+",
-	"[":  "{",
-	"]":  "}",
-	"\\": "|",
-	";":  ":",
-	"'":  "\"",
-	",":  "<",
-	".":  ">",
-	"/":  "?",
-	"~":  "`",
-	"!":  "1",
-	"@":  "2",
-	"#":  "3",
-	"$":  "4",
-	"%":  "5",
-	"^":  "6",
-	"&":  "7",
-	"*":  "8",
-	"(":  "9",
-	")":  "0",
-	"_":  "-",
-	"+":  "=",
-	"{":  "[",
-	"}":  "]",
-	"|":  "\\",
-	":":  ";",
-	"\"": "'",
-	"<":  ",",
-	">":  ".",
-	"?":  "/",
-}
-
-// TODO extend to use any rune instead of US alphanumerics
+// LetterRunes are the US alphanumerics, use Layout.Runes for other keyboard layouts
 var LetterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ`1234567890-=[]\\;',./~!@#$%^&*()_+{}|:\"<>?")
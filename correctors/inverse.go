@@ -0,0 +1,119 @@
+package correctors
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// specialCaseRunes are the runes whose case mapping changes the length of the string or depends on the locale
+var specialCaseRunes = []rune("ßẞıİiIſﬀﬁﬂ")
+
+// typedWhitespace are the whitespace characters inverted by the whitespace correctors, the pre-image of those correctors
+// is infinite so Inverse only returns the typos with a single stray whitespace character
+var typedWhitespace = []rune{' ', '\t', '\u00a0', '\u3000'}
+
+// exactInverse returns the typos for which the correction function returns the password, without duplicates.
+// The password itself is never part of the inverse, it's already the submitted password
+func exactInverse(password string, typos []string, apply func(string) string) []string {
+	inverse := make([]string, 0)
+	seen := map[string]bool{password: true}
+
+	for _, typo := range typos {
+		if !seen[typo] && apply(typo) == password {
+			seen[typo] = true
+			inverse = append(inverse, typo)
+		}
+	}
+
+	return inverse
+}
+
+// maxRuneInverse is the maximum number of strings returned by runeInverse, the number of strings mapped to a password
+// grows exponentially with its length e.g. every k of a password can be typed as a k or a Kelvin sign
+const maxRuneInverse = 1000
+
+// runeInverse returns the strings made of the candidate runes that are mapped to the password when f is applied
+// to each of its runes, at most maxRuneInverse of them
+func runeInverse(password string, candidates []rune, f func(rune) string) []string {
+	// the runes that can produce each mapped string e.g. "SS" <= ß
+	sources := make(map[string][]rune)
+	outputs := make([]string, 0)
+	for _, candidate := range uniqueRunes(candidates) {
+		output := f(candidate)
+		if output == "" {
+			continue
+		}
+		if _, ok := sources[output]; !ok {
+			outputs = append(outputs, output)
+		}
+		sources[output] = append(sources[output], candidate)
+	}
+
+	// inverses of the suffixes of the password starting at each byte offset
+	suffixes := make(map[int][]string)
+	var inverseFrom func(offset int) []string
+	inverseFrom = func(offset int) []string {
+		if offset == len(password) {
+			return []string{""}
+		}
+
+		if inverse, ok := suffixes[offset]; ok {
+			return inverse
+		}
+
+		inverse := make([]string, 0)
+		for _, output := range outputs {
+			if !strings.HasPrefix(password[offset:], output) {
+				continue
+			}
+
+			for _, rest := range inverseFrom(offset + len(output)) {
+				for _, source := range sources[output] {
+					if len(inverse) == maxRuneInverse {
+						break
+					}
+					inverse = append(inverse, string(source)+rest)
+				}
+			}
+		}
+
+		suffixes[offset] = inverse
+		return inverse
+	}
+
+	return inverseFrom(0)
+}
+
+// caseCandidates returns the runes that can be mapped to the runes of the password by a case mapping
+func caseCandidates(password string, locale language.Tag) []rune {
+	candidates := append([]rune(password), specialCaseRunes...)
+	candidates = append(candidates, []rune(cases.Lower(locale).String(password))...)
+	candidates = append(candidates, []rune(cases.Upper(locale).String(password))...)
+
+	for _, character := range password {
+		// every rune that is equivalent under simple case folding e.g. k, K and the Kelvin sign
+		for folded := unicode.SimpleFold(character); folded != character; folded = unicode.SimpleFold(folded) {
+			candidates = append(candidates, folded)
+		}
+	}
+
+	return uniqueRunes(candidates)
+}
+
+// uniqueRunes returns the runes without duplicates, in the same order
+func uniqueRunes(runes []rune) []rune {
+	unique := make([]rune, 0, len(runes))
+	seen := make(map[rune]bool)
+
+	for _, character := range runes {
+		if !seen[character] {
+			seen[character] = true
+			unique = append(unique, character)
+		}
+	}
+
+	return unique
+}
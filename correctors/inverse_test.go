@@ -0,0 +1,115 @@
+package correctors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+// inverseTestPasswords are passwords and typos that exercise every corrector at least once
+var inverseTestPasswords = []string{
+	"password", "Password", "PASSWORD", "pAssword", "PaSsWoRd", "password1", "password!", "Password1!", "PASSWORD1",
+	"passwor", "psasword", "passsword", "pass word", "pass  word", " password", "password ", "зфыыцщкв", "ｐａｓｓ",
+	"istanbul", "İSTANBUL", "straße", "1234", "a",
+}
+
+var inverseTestOptions = []*Options{
+	nil,
+	{Layout: FrenchAzerty},
+	{Layout: GermanQwertz, Locale: language.German},
+	{Locale: language.Turkish},
+}
+
+// every password returned by Inverse must be corrected to the given password
+func TestInverseIsPreImage(t *testing.T) {
+	for _, options := range inverseTestOptions {
+		for _, name := range Registered() {
+			corrector, err := LookupWithOptions(name, options)
+			assert.Nil(t, err)
+
+			for _, password := range inverseTestPasswords {
+				for _, typo := range corrector.Inverse(password) {
+					assert.NotEqual(t, password, typo, "%s should not return the password in its inverse", name)
+					assert.Contains(t, applyAll(corrector, typo), password, "%s should correct %q to %q", name, typo, password)
+				}
+			}
+		}
+	}
+}
+
+// every typo corrected to a password must be returned by Inverse
+func TestInverseIsComplete(t *testing.T) {
+	for _, options := range inverseTestOptions {
+		layout := USQwerty
+		if options != nil && options.Layout != nil {
+			layout = options.Layout
+		}
+
+		for _, name := range Registered() {
+			corrector, err := LookupWithOptions(name, options)
+			assert.Nil(t, err)
+
+			for _, typo := range inverseTestPasswords {
+				// the characters removed by rm-first and rm-last are inverted with the characters of the layout
+				if (name == RemoveFirst || name == RemoveLast) && !typeable(typo, layout) {
+					continue
+				}
+
+				for _, password := range applyAll(corrector, typo) {
+					if password == typo || password == "" {
+						continue
+					}
+					assert.Contains(t, corrector.Inverse(password), typo, "%s should invert %q to %q", name, password, typo)
+				}
+			}
+		}
+	}
+}
+
+// typeable returns true if every character of the password is on the layout
+func typeable(password string, layout *Layout) bool {
+	for _, character := range password {
+		if _, ok := layout.Position(character); !ok && character != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRuneInverse(t *testing.T) {
+	inverse := runeInverse("SS", []rune("sSß"), func(character rune) string {
+		return switchCase(character, language.Und)
+	})
+	assert.ElementsMatch(t, []string{"ss", "ß"}, inverse)
+}
+
+func TestInverseRemoveLast(t *testing.T) {
+	inverse := InverseRemoveLast("password")
+	assert.Len(t, inverse, len(USQwerty.Runes())+1)
+	assert.NotContains(t, inverse, "", "should not return empty passwords")
+	assert.Contains(t, inverse, "password1")
+}
+
+func TestInverseAppendOne(t *testing.T) {
+	assert.Equal(t, []string{"password"}, inverseAppendOne("password1"))
+	assert.Empty(t, inverseAppendOne("password"))
+}
+
+func TestInverseCapitalToUpper(t *testing.T) {
+	inverse := inverseCapitalToUpper("AB", language.Und)
+	assert.ElementsMatch(t, []string{"Ab"}, inverse)
+	assert.Empty(t, inverseCapitalToUpper("password", language.Und))
+}
+
+// the inverse of long passwords must stay small, the pre-image of the case correctors is exponential in the length
+func TestInverseLongPassword(t *testing.T) {
+	upper := strings.Repeat("PASSWORD", 8)
+	inverse := inverseCapitalToUpper(upper, language.Und)
+	assert.Equal(t, []string{"P" + strings.Repeat("password", 8)[1:]}, inverse)
+
+	inverse = inverseSwitchCaseAll(strings.Repeat("k", 64), language.Und)
+	assert.LessOrEqual(t, len(inverse), maxRuneInverse)
+	assert.Contains(t, inverse, strings.Repeat("K", 64))
+}
@@ -0,0 +1,136 @@
+package correctors
+
+import (
+	"errors"
+	"sort"
+)
+
+// keyClose is the kclose corrector, it substitutes a single character with one of its adjacent keys
+type keyClose struct {
+	// max is the maximum number of corrections added to the ball
+	max    int
+	layout *Layout
+}
+
+// substitution is a candidate correction and its likelihood
+type substitution struct {
+	password string
+	weight   float64
+}
+
+func newKeyClose(parameters Parameters) (Corrector, error) {
+	max, err := parameters.Int("max", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	if max < 1 {
+		return nil, errors.New("parameter max must be at least 1")
+	}
+
+	return &keyClose{max: max, layout: USQwerty}, nil
+}
+
+func (c *keyClose) Name() string {
+	return KeyClose
+}
+
+func (c *keyClose) Apply(password string) string {
+	corrections := c.ApplyAll(password)
+	if len(corrections) == 0 {
+		return password
+	}
+	return corrections[0]
+}
+
+// ApplyAll returns at most max adjacent key substitutions, the closest keys first
+func (c *keyClose) ApplyAll(password string) []string {
+	substitutions := KeyCloseSubstitutions(password, c.layout)
+
+	if len(substitutions) > c.max {
+		substitutions = substitutions[:c.max]
+	}
+
+	return substitutions
+}
+
+// Inverse returns the passwords for which the corrector returns the given password
+func (c *keyClose) Inverse(password string) []string {
+	inverse := make([]string, 0)
+
+	for _, typo := range substituteAdjacentTo(password, c.layout) {
+		if !StringInSlice(typo, inverse) && StringInSlice(password, c.ApplyAll(typo)) {
+			inverse = append(inverse, typo)
+		}
+	}
+
+	return inverse
+}
+
+func (c *keyClose) Adapt(options *Options) Corrector {
+	adapted := *c
+	if options.Layout != nil {
+		adapted.layout = options.Layout
+	}
+	return &adapted
+}
+
+// KeyCloseSubstitutions returns every password obtained by substituting a single character with an adjacent key.
+// They are ordered by the proximity of the keys then by the position of the character in the password
+// kclose
+func KeyCloseSubstitutions(password string, layout *Layout) []string {
+	runes := []rune(password)
+	substitutions := make([]substitution, 0)
+	seen := map[string]bool{password: true}
+
+	for index, character := range runes {
+		for _, neighbour := range layout.Neighbours(character) {
+			substituted := make([]rune, len(runes))
+			copy(substituted, runes)
+			substituted[index] = neighbour.Character
+
+			if !seen[string(substituted)] {
+				seen[string(substituted)] = true
+				substitutions = append(substitutions, substitution{string(substituted), neighbour.Weight})
+			}
+		}
+	}
+
+	sort.SliceStable(substitutions, func(i, j int) bool {
+		return substitutions[i].weight > substitutions[j].weight
+	})
+
+	passwords := make([]string, len(substitutions))
+	for index, substitution := range substitutions {
+		passwords[index] = substitution.password
+	}
+
+	return passwords
+}
+
+// substituteAdjacentTo returns the passwords where a character is substituted by a key that has it as a neighbour.
+// Adjacency isn't always symmetric e.g. keys that type the same character with and without shift
+func substituteAdjacentTo(password string, layout *Layout) []string {
+	runes := []rune(password)
+	substitutions := make([]string, 0)
+
+	for index, character := range runes {
+		for _, typo := range adjacentTo(character, layout) {
+			substituted := make([]rune, len(runes))
+			copy(substituted, runes)
+			substituted[index] = typo
+			substitutions = append(substitutions, string(substituted))
+		}
+	}
+
+	return substitutions
+}
+
+// adjacentTo returns the characters that have the given character as a neighbour
+func adjacentTo(character rune, layout *Layout) []rune {
+	return layout.adjacentTo[character]
+}
+
+func init() {
+	Register(KeyClose, newKeyClose)
+}
@@ -0,0 +1,58 @@
+package correctors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyCloseSubstitutions(t *testing.T) {
+	substitutions := KeyCloseSubstitutions("qa", USQwerty)
+	if !assert.Equal(t, substitutions[:3], []string{"wa", "qs", "aa"}) {
+		t.Errorf("should substitute the keys on the same row first")
+	}
+
+	if !assert.Subset(t, substitutions, []string{"1a", "2a", "qq", "qw", "qz"}) {
+		t.Errorf("should substitute the keys on the rows above and below")
+	}
+
+	if !assert.Subset(t, KeyCloseSubstitutions("Q", USQwerty), []string{"W", "A", "!", "@"}) {
+		t.Errorf("should keep the shift modifier of the key")
+	}
+
+	if !assert.Subset(t, KeyCloseSubstitutions("a", FrenchAzerty), []string{"z", "q"}) {
+		t.Errorf("should use the keyboard layout")
+	}
+}
+
+func TestKeyClose(t *testing.T) {
+	corrector, err := New(KeyClose, Parameters{"max": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kclose := corrector.(MultiCorrector)
+	if len(kclose.ApplyAll("password")) != 3 {
+		t.Errorf("should limit the number of substitutions in the ball")
+	}
+
+	if kclose.Apply("password") != "oassword" {
+		t.Errorf("should return the most likely substitution")
+	}
+
+	if !assert.Contains(t, kclose.ApplyAll("psssword"), "password") {
+		t.Errorf("should correct an adjacent key typo")
+	}
+
+	for _, typo := range kclose.Inverse("password") {
+		if !assert.Contains(t, kclose.ApplyAll(typo), "password") {
+			t.Errorf("should only return typos that are corrected to the password")
+		}
+	}
+
+	ball, err := GetBallWithCorrectionType("password", []string{KeyClose})
+	assert.Nil(t, err)
+	if len(ball) != 10 || ball["oassword"] != KeyClose {
+		t.Errorf("should add every substitution to the ball with the kclose correction type")
+	}
+}
@@ -0,0 +1,191 @@
+package correctors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Edit constants, the kinds of single keypress edits tried by keypress-edit
+const (
+	// RemoveDoubled removes a key that was pressed twice e.g. passsword => password
+	RemoveDoubled = "rm-doubled"
+	// AddDoubled repeats a letter that was only typed once e.g. pasword => password
+	AddDoubled = "add-doubled"
+	// RemoveAdjacent removes a key that was hit next to the intended one e.g. passwoird => password
+	RemoveAdjacent = "rm-adjacent"
+	// SubstituteAdjacent substitutes a key with an adjacent one e.g. passeord => password
+	SubstituteAdjacent = "sub-adjacent"
+)
+
+// defaultEditPrior is the order in which edits are tried by default, from the most to the least likely
+var defaultEditPrior = []string{RemoveDoubled, AddDoubled, RemoveAdjacent, SubstituteAdjacent}
+
+// keypressEdit is the keypress-edit corrector, it inserts, deletes or substitutes a character at a single position
+type keypressEdit struct {
+	// prior is the order in which edits are added to the ball
+	prior []string
+	// max is the maximum number of corrections added to the ball
+	max    int
+	layout *Layout
+}
+
+func newKeypressEdit(parameters Parameters) (Corrector, error) {
+	max, err := parameters.Int("max", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	if max < 1 {
+		return nil, errors.New("parameter max must be at least 1")
+	}
+
+	prior := parameters.Strings("prior", defaultEditPrior)
+	if len(prior) == 0 {
+		return nil, errors.New("parameter prior must contain at least one edit")
+	}
+
+	for _, edit := range prior {
+		if !StringInSlice(edit, defaultEditPrior) {
+			return nil, errors.New("unknown edit " + edit + " - use one of: " + strings.Join(defaultEditPrior, ", "))
+		}
+	}
+
+	return &keypressEdit{prior: prior, max: max, layout: USQwerty}, nil
+}
+
+func (c *keypressEdit) Name() string {
+	return KeypressEdit
+}
+
+func (c *keypressEdit) Apply(password string) string {
+	corrections := c.ApplyAll(password)
+	if len(corrections) == 0 {
+		return password
+	}
+	return corrections[0]
+}
+
+// ApplyAll returns at most max edits of the password, in the order of the prior
+func (c *keypressEdit) ApplyAll(password string) []string {
+	corrections := make([]string, 0)
+	seen := map[string]bool{password: true, "": true}
+
+	for _, edit := range c.prior {
+		for _, correction := range KeypressEdits(password, edit, c.layout) {
+			if len(corrections) == c.max {
+				return corrections
+			}
+
+			if !seen[correction] {
+				seen[correction] = true
+				corrections = append(corrections, correction)
+			}
+		}
+	}
+
+	return corrections
+}
+
+// Inverse returns the passwords for which the corrector returns the given password
+func (c *keypressEdit) Inverse(password string) []string {
+	inverse := make([]string, 0)
+	seen := map[string]bool{password: true}
+
+	// every edit is undone by another kind of edit
+	undo := map[string]string{
+		RemoveDoubled: AddDoubled,
+		AddDoubled:    RemoveDoubled,
+	}
+
+	for _, edit := range c.prior {
+		var typos []string
+		switch edit {
+		case RemoveAdjacent:
+			typos = insertAdjacent(password, c.layout)
+		case SubstituteAdjacent:
+			typos = substituteAdjacentTo(password, c.layout)
+		default:
+			typos = KeypressEdits(password, undo[edit], c.layout)
+		}
+
+		for _, typo := range typos {
+			if !seen[typo] && StringInSlice(password, c.ApplyAll(typo)) {
+				seen[typo] = true
+				inverse = append(inverse, typo)
+			}
+		}
+	}
+
+	return inverse
+}
+
+func (c *keypressEdit) Adapt(options *Options) Corrector {
+	adapted := *c
+	if options.Layout != nil {
+		adapted.layout = options.Layout
+	}
+	return &adapted
+}
+
+// KeypressEdits returns the passwords obtained by applying a single edit to the password, from left to right
+// keypress-edit
+func KeypressEdits(password string, edit string, layout *Layout) []string {
+	runes := []rune(password)
+	edits := make([]string, 0)
+
+	switch edit {
+	case RemoveDoubled:
+		for i := 1; i < len(runes); i++ {
+			if runes[i] == runes[i-1] {
+				edits = append(edits, string(runes[:i])+string(runes[i+1:]))
+			}
+		}
+	case AddDoubled:
+		for i := range runes {
+			edits = append(edits, string(runes[:i+1])+string(runes[i:]))
+		}
+	case RemoveAdjacent:
+		for i := range runes {
+			if (i > 0 && adjacent(runes[i], runes[i-1], layout)) || (i < len(runes)-1 && adjacent(runes[i], runes[i+1], layout)) {
+				edits = append(edits, string(runes[:i])+string(runes[i+1:]))
+			}
+		}
+	case SubstituteAdjacent:
+		edits = KeyCloseSubstitutions(password, layout)
+	}
+
+	return edits
+}
+
+// insertAdjacent returns the passwords obtained by inserting a key adjacent to one of its neighbours in the password
+func insertAdjacent(password string, layout *Layout) []string {
+	runes := []rune(password)
+	edits := make([]string, 0)
+
+	for i := 0; i <= len(runes); i++ {
+		for _, j := range []int{i - 1, i} {
+			if j < 0 || j >= len(runes) {
+				continue
+			}
+			for _, character := range adjacentTo(runes[j], layout) {
+				edits = append(edits, string(runes[:i])+string(character)+string(runes[i:]))
+			}
+		}
+	}
+
+	return edits
+}
+
+// adjacent returns true if the characters are on adjacent keys
+func adjacent(a rune, b rune, layout *Layout) bool {
+	for _, neighbour := range layout.Neighbours(a) {
+		if neighbour.Character == b {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	Register(KeypressEdit, newKeypressEdit)
+}
@@ -0,0 +1,72 @@
+package correctors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeypressEdits(t *testing.T) {
+	if !assert.Equal(t, KeypressEdits("passsword", RemoveDoubled, USQwerty), []string{"password", "password"}) {
+		t.Errorf("should remove keys that were pressed twice")
+	}
+
+	if !assert.Contains(t, KeypressEdits("pasword", AddDoubled, USQwerty), "password") {
+		t.Errorf("should repeat letters that were only typed once")
+	}
+
+	if !assert.Contains(t, KeypressEdits("passwoird", RemoveAdjacent, USQwerty), "password") {
+		t.Errorf("should remove keys hit next to the intended key")
+	}
+
+	if !assert.NotContains(t, KeypressEdits("passwoxrd", RemoveAdjacent, USQwerty), "password") {
+		t.Errorf("should only remove keys next to their neighbours in the password")
+	}
+
+	if !assert.Contains(t, KeypressEdits("passeord", SubstituteAdjacent, USQwerty), "password") {
+		t.Errorf("should substitute adjacent keys")
+	}
+}
+
+func TestKeypressEdit(t *testing.T) {
+	corrector, err := Lookup(KeypressEdit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keypressEdit := corrector.(MultiCorrector)
+
+	if keypressEdit.Apply("passsword") != "password" {
+		t.Errorf("should return the first edit of the prior")
+	}
+
+	if len(keypressEdit.ApplyAll("password")) != 10 {
+		t.Errorf("should limit the number of edits in the ball")
+	}
+
+	corrector, err = New(KeypressEdit, Parameters{"prior": "add-doubled, rm-doubled", "max": "20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keypressEdit = corrector.(MultiCorrector)
+
+	if keypressEdit.Apply("passsword") != "ppasssword" {
+		t.Errorf("should use the configured prior")
+	}
+
+	if !assert.Contains(t, keypressEdit.ApplyAll("passsword"), "password") {
+		t.Errorf("should try every edit of the prior")
+	}
+
+	for _, typo := range keypressEdit.Inverse("password") {
+		if !assert.Contains(t, keypressEdit.ApplyAll(typo), "password") {
+			t.Errorf("should only return typos that are corrected to the password")
+		}
+	}
+	if !assert.Subset(t, keypressEdit.Inverse("password"), []string{"passsword", "pasword"}) {
+		t.Errorf("should return the typos of the password")
+	}
+
+	if _, err = New(KeypressEdit, Parameters{"prior": "rm-everything"}); err == nil {
+		t.Errorf("should reject unknown edits")
+	}
+}
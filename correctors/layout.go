@@ -0,0 +1,342 @@
+package correctors
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Layout constants
+const (
+	US     = "us"
+	UK     = "uk"
+	Azerty = "azerty"
+	Qwertz = "qwertz"
+	Dvorak = "dvorak"
+	// Russian and Greek are mostly used to correct passwords typed with the wrong input language, see wrong-layout
+	Russian = "ru"
+	Greek   = "el"
+)
+
+// Layout represents a keyboard layout, rows are listed from the top of the keyboard
+type Layout struct {
+	Name string `yaml:"name"`
+	Rows []Row  `yaml:"rows"`
+
+	// lookup tables built from the rows
+	shift      map[rune]rune
+	positions  map[rune]Position
+	runes      []rune
+	neighbours map[rune][]Neighbour
+	// adjacentTo are the characters that have the character as a neighbour
+	adjacentTo map[rune][]rune
+}
+
+// Row represents a row of keys on the keyboard
+type Row struct {
+	// Offset of the first key from the left edge of the keyboard in key widths e.g. 1.5 for the tab row
+	Offset float64 `yaml:"offset"`
+	// Unshifted are the characters typed without the shift modifier
+	Unshifted string `yaml:"unshifted"`
+	// Shifted are the characters typed with the shift modifier, in the same order as Unshifted
+	Shifted string `yaml:"shifted"`
+}
+
+// Position represents the position of a character on the keyboard
+type Position struct {
+	Row     int
+	Column  int
+	Shifted bool
+}
+
+// NewLayout creates a layout from its rows of keys
+func NewLayout(name string, rows []Row) (*Layout, error) {
+	layout := &Layout{
+		Name:      name,
+		Rows:      rows,
+		shift:     make(map[rune]rune),
+		positions: make(map[rune]Position),
+	}
+
+	if name == "" {
+		return nil, errors.New("layout must have a name")
+	}
+
+	for rowIndex, row := range rows {
+		unshifted := []rune(row.Unshifted)
+		shifted := []rune(row.Shifted)
+
+		if len(unshifted) != len(shifted) {
+			return nil, errors.New("layout " + name + " must have as many shifted as unshifted keys in every row")
+		}
+
+		for column := range unshifted {
+			layout.addKey(unshifted[column], Position{rowIndex, column, false})
+			layout.addKey(shifted[column], Position{rowIndex, column, true})
+
+			// keys without a shifted character repeat the unshifted one
+			if unshifted[column] != shifted[column] {
+				layout.shift[unshifted[column]] = shifted[column]
+				layout.shift[shifted[column]] = unshifted[column]
+			}
+		}
+	}
+
+	// adjacency isn't always symmetric e.g. for keys that type the same character with and without shift
+	layout.neighbours = make(map[rune][]Neighbour)
+	layout.adjacentTo = make(map[rune][]rune)
+	for _, character := range layout.runes {
+		layout.neighbours[character] = layout.findNeighbours(character)
+		for _, neighbour := range layout.neighbours[character] {
+			if neighbour.Character != character {
+				layout.adjacentTo[neighbour.Character] = append(layout.adjacentTo[neighbour.Character], character)
+			}
+		}
+	}
+
+	return layout, nil
+}
+
+func (layout *Layout) addKey(character rune, position Position) {
+	// the first key wins if a character appears twice
+	if _, ok := layout.positions[character]; !ok {
+		layout.positions[character] = position
+		layout.runes = append(layout.runes, character)
+	}
+}
+
+// LoadLayout loads a custom keyboard layout from a yaml file
+func LoadLayout(filename string) (*Layout, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &Layout{}
+	err = yaml.UnmarshalStrict(content, file)
+	if err != nil {
+		return nil, errors.New("could not unmarshal layout file " + filename + " : " + err.Error())
+	}
+
+	return NewLayout(file.Name, file.Rows)
+}
+
+// Shift returns the character typed on the same key with the opposite shift modifier
+func (layout *Layout) Shift(character rune) (rune, bool) {
+	if shifted, ok := layout.shift[character]; ok {
+		return shifted, true
+	}
+
+	// letters that aren't on the layout e.g. accented letters typed with a dead key
+	if unicode.IsUpper(character) {
+		return unicode.ToLower(character), true
+	} else if unicode.IsLower(character) {
+		return unicode.ToUpper(character), true
+	}
+
+	return character, false
+}
+
+// Position returns the position of the key used to type the character
+func (layout *Layout) Position(character rune) (Position, bool) {
+	position, ok := layout.positions[character]
+	return position, ok
+}
+
+// At returns the character typed at the given position
+func (layout *Layout) At(position Position) (rune, bool) {
+	if position.Row < 0 || position.Row >= len(layout.Rows) {
+		return utf8.RuneError, false
+	}
+
+	keys := []rune(layout.Rows[position.Row].Unshifted)
+	if position.Shifted {
+		keys = []rune(layout.Rows[position.Row].Shifted)
+	}
+
+	if position.Column < 0 || position.Column >= len(keys) {
+		return utf8.RuneError, false
+	}
+
+	return keys[position.Column], true
+}
+
+// Neighbour is a key next to another key, the weight is higher for keys that are more likely to be hit by mistake
+type Neighbour struct {
+	Character rune
+	Weight    float64
+}
+
+// Neighbours returns the characters typed with the same shift modifier on the adjacent keys, from the closest to the furthest
+func (layout *Layout) Neighbours(character rune) []Neighbour {
+	return layout.neighbours[character]
+}
+
+func (layout *Layout) findNeighbours(character rune) []Neighbour {
+	position, ok := layout.Position(character)
+	if !ok {
+		return nil
+	}
+
+	neighbours := make([]Neighbour, 0)
+	x := layout.Rows[position.Row].Offset + float64(position.Column)
+
+	for row := position.Row - 1; row <= position.Row+1; row++ {
+		if row < 0 || row >= len(layout.Rows) {
+			continue
+		}
+
+		for column := range []rune(layout.Rows[row].Unshifted) {
+			distance := math.Abs(layout.Rows[row].Offset + float64(column) - x)
+
+			weight := 0.0
+			if row == position.Row && (column == position.Column-1 || column == position.Column+1) {
+				// keys on the same row are the most likely to be hit by mistake
+				weight = 1
+			} else if row != position.Row && distance < 1 {
+				// keys on the rows above and below overlap the key
+				weight = 1 - distance
+			}
+
+			if weight > 0 {
+				neighbour, _ := layout.At(Position{row, column, position.Shifted})
+				neighbours = append(neighbours, Neighbour{neighbour, weight})
+			}
+		}
+	}
+
+	sort.SliceStable(neighbours, func(i, j int) bool {
+		return neighbours[i].Weight > neighbours[j].Weight
+	})
+
+	return neighbours
+}
+
+// Runes returns every character that can be typed on the layout
+func (layout *Layout) Runes() []rune {
+	return layout.runes
+}
+
+var (
+	layoutsMutex sync.RWMutex
+	layouts      = make(map[string]*Layout)
+)
+
+// RegisterLayout makes a layout available by name e.g. for layouts loaded with LoadLayout
+func RegisterLayout(layout *Layout) error {
+	layoutsMutex.Lock()
+	defer layoutsMutex.Unlock()
+
+	if _, duplicate := layouts[layout.Name]; duplicate {
+		return errors.New("layout " + layout.Name + " is already registered")
+	}
+
+	layouts[layout.Name] = layout
+	return nil
+}
+
+// GetLayout returns the layout with the given name
+func GetLayout(name string) (*Layout, error) {
+	layoutsMutex.RLock()
+	defer layoutsMutex.RUnlock()
+
+	layout, ok := layouts[name]
+	if !ok {
+		return nil, errors.New("layout unknown: " + name)
+	}
+
+	return layout, nil
+}
+
+// Layouts returns the sorted names of every registered layout
+func Layouts() []string {
+	layoutsMutex.RLock()
+	defer layoutsMutex.RUnlock()
+
+	names := make([]string, 0, len(layouts))
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func mustLayout(name string, rows []Row) *Layout {
+	layout, err := NewLayout(name, rows)
+	if err != nil {
+		panic(err)
+	}
+
+	err = RegisterLayout(layout)
+	if err != nil {
+		panic(err)
+	}
+
+	return layout
+}
+
+// Built-in layouts
+var (
+	// USQwerty is the US ANSI QWERTY layout, it is used when no other layout is set
+	USQwerty = mustLayout(US, []Row{
+		{0, "`1234567890-=", "~!@#$%^&*()_+"},
+		{1.5, "qwertyuiop[]\\", "QWERTYUIOP{}|"},
+		{1.75, "asdfghjkl;'", "ASDFGHJKL:\""},
+		{2.25, "zxcvbnm,./", "ZXCVBNM<>?"},
+	})
+
+	// UKQwerty is the UK ISO QWERTY layout
+	UKQwerty = mustLayout(UK, []Row{
+		{0, "`1234567890-=", "¬!\"£$%^&*()_+"},
+		{1.5, "qwertyuiop[]", "QWERTYUIOP{}"},
+		{1.75, "asdfghjkl;'#", "ASDFGHJKL:@~"},
+		{1.25, "\\zxcvbnm,./", "|ZXCVBNM<>?"},
+	})
+
+	// FrenchAzerty is the French ISO AZERTY layout
+	FrenchAzerty = mustLayout(Azerty, []Row{
+		{0, "²&é\"'(-è_çà)=", "²1234567890°+"},
+		{1.5, "azertyuiop^$", "AZERTYUIOP¨£"},
+		{1.75, "qsdfghjklmù*", "QSDFGHJKLM%µ"},
+		{1.25, "<wxcvbn,;:!", ">WXCVBN?./§"},
+	})
+
+	// GermanQwertz is the German ISO QWERTZ layout
+	GermanQwertz = mustLayout(Qwertz, []Row{
+		{0, "^1234567890ß´", "°!\"§$%&/()=?`"},
+		{1.5, "qwertzuiopü+", "QWERTZUIOPÜ*"},
+		{1.75, "asdfghjklöä#", "ASDFGHJKLÖÄ'"},
+		{1.25, "<yxcvbnm,.-", ">YXCVBNM;:_"},
+	})
+
+	// USDvorak is the US ANSI Dvorak layout
+	USDvorak = mustLayout(Dvorak, []Row{
+		{0, "`1234567890[]", "~!@#$%^&*(){}"},
+		{1.5, "',.pyfgcrl/=\\", "\"<>PYFGCRL?+|"},
+		{1.75, "aoeuidhtns-", "AOEUIDHTNS_"},
+		{2.25, ";qjkxbmwvz", ":QJKXBMWVZ"},
+	})
+
+	// RussianJcuken is the Russian JCUKEN layout
+	RussianJcuken = mustLayout(Russian, []Row{
+		{0, "ё1234567890-=", "Ё!\"№;%:?*()_+"},
+		{1.5, "йцукенгшщзхъ\\", "ЙЦУКЕНГШЩЗХЪ/"},
+		{1.75, "фывапролджэ", "ФЫВАПРОЛДЖЭ"},
+		{2.25, "ячсмитьбю.", "ЯЧСМИТЬБЮ,"},
+	})
+
+	// GreekQwerty is the Greek layout
+	GreekQwerty = mustLayout(Greek, []Row{
+		{0, "`1234567890-=", "~!@#$%^&*()_+"},
+		{1.5, ";ςερτυθιοπ[]\\", ":΅ΕΡΤΥΘΙΟΠ{}|"},
+		{1.75, "ασδφγηξκλ΄'", "ΑΣΔΦΓΗΞΚΛ¨\""},
+		{2.25, "ζχψωβνμ,./", "ΖΧΨΩΒΝΜ<>?"},
+	})
+)
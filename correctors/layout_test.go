@@ -0,0 +1,106 @@
+package correctors
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutShift(t *testing.T) {
+	if shifted, _ := USQwerty.Shift('1'); shifted != '!' {
+		t.Errorf("should switch 1 to ! on a US keyboard")
+	}
+
+	if shifted, _ := FrenchAzerty.Shift('&'); shifted != '1' {
+		t.Errorf("should switch & to 1 on an AZERTY keyboard")
+	}
+
+	if shifted, _ := GermanQwertz.Shift('2'); shifted != '"' {
+		t.Errorf("should switch 2 to \" on a QWERTZ keyboard")
+	}
+
+	if shifted, _ := UKQwerty.Shift('2'); shifted != '"' {
+		t.Errorf("should switch 2 to \" on a UK keyboard")
+	}
+
+	if shifted, _ := GermanQwertz.Shift('ü'); shifted != 'Ü' {
+		t.Errorf("should switch the case of letters")
+	}
+
+	if _, ok := USQwerty.Shift('²'); ok {
+		t.Errorf("should not switch characters that aren't on the layout")
+	}
+}
+
+func TestLayoutPosition(t *testing.T) {
+	position, ok := FrenchAzerty.Position('A')
+	if !ok || position != (Position{1, 0, true}) {
+		t.Errorf("should return the row, column and shift modifier of the key")
+	}
+
+	if character, _ := USQwerty.At(position); character != 'Q' {
+		t.Errorf("should return the character at the same position on another layout")
+	}
+}
+
+func TestShiftCorrectorsWithLayout(t *testing.T) {
+	azerty := &Options{Layout: FrenchAzerty}
+
+	corrector, _ := LookupWithOptions(SwitchLast, azerty)
+	if corrector.Apply("password1") != "password&" {
+		t.Errorf("should switch the last character using the AZERTY layout")
+	}
+
+	corrector, _ = LookupWithOptions(SwitchLastN, &Options{Layout: GermanQwertz})
+	if corrector.Apply("password12") != "password!\"" {
+		t.Errorf("should switch the last characters using the QWERTZ layout")
+	}
+
+	corrector, _ = LookupWithOptions(NumberToSymbolLast, azerty)
+	if corrector.Apply("password1") != "password&" {
+		t.Errorf("should convert the last number to a symbol using the AZERTY layout")
+	}
+
+	corrector, _ = Lookup(SwitchLast)
+	if corrector.Apply("password1") != "password!" {
+		t.Errorf("should use the US layout by default")
+	}
+
+	corrections, err := GetCorrections("password1", []string{SwitchAll, SwitchLast}, azerty)
+	assert.Nil(t, err)
+	ball := Passwords(corrections)
+	if !assert.ElementsMatch(t, ball, []string{"PASSWORD1", "password&"}) {
+		t.Errorf("should build the ball using the layout")
+	}
+}
+
+func TestLoadLayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "layout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "numpad.yml")
+	err = ioutil.WriteFile(filename, []byte("name: numpad\nrows:\n  - offset: 0\n    unshifted: \"789\"\n    shifted: \"&*(\"\n"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layout, err := LoadLayout(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if shifted, _ := layout.Shift('8'); shifted != '*' {
+		t.Errorf("should load the shifted pairs from the file")
+	}
+
+	_, err = NewLayout("broken", []Row{{0, "123", "!@"}})
+	if err == nil {
+		t.Errorf("should reject rows with a different number of shifted and unshifted keys")
+	}
+}
@@ -0,0 +1,379 @@
+package correctors
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// Corrector is a typo correction function e.g. swc-all
+type Corrector interface {
+	// Name is the name used in tipsy.yml and the typo distribution e.g. swc-all
+	Name() string
+	// Apply corrects the typo in the submitted password
+	Apply(password string) string
+	// Inverse returns every password, other than the given one, that the corrector corrects to the given password.
+	// The passwords that add or remove characters are built from the runes of the keyboard layout
+	Inverse(password string) []string
+}
+
+// MultiCorrector is implemented by correctors that can correct a typo in several ways e.g. kclose
+type MultiCorrector interface {
+	Corrector
+	// ApplyAll returns the corrected passwords from the most to the least likely, Apply returns the first one
+	ApplyAll(password string) []string
+}
+
+// Options are the per-user or per-request settings used to correct a password e.g. the keyboard layout
+type Options struct {
+	Layout *Layout
+	// Locale is used by the case correctors e.g. tr maps i to İ
+	Locale language.Tag
+	// Depth is the maximum number of correctors chained to correct a password, 1 if it isn't set
+	Depth int
+	// MaxBallSize is the maximum number of passwords in the ball, unlimited if it isn't set
+	MaxBallSize int
+}
+
+// Merge returns a copy of the options overridden by the fields that are set in other
+func (options *Options) Merge(other *Options) *Options {
+	merged := &Options{}
+	if options != nil {
+		*merged = *options
+	}
+
+	if other != nil && other.Layout != nil {
+		merged.Layout = other.Layout
+	}
+
+	if other != nil && other.Locale != language.Und {
+		merged.Locale = other.Locale
+	}
+
+	if other != nil && other.Depth > 0 {
+		merged.Depth = other.Depth
+	}
+
+	if other != nil && other.MaxBallSize > 0 {
+		merged.MaxBallSize = other.MaxBallSize
+	}
+
+	return merged
+}
+
+// Adaptive is implemented by correctors that depend on the Options e.g. on the keyboard layout
+type Adaptive interface {
+	// Adapt returns a copy of the corrector that uses the given options
+	Adapt(options *Options) Corrector
+}
+
+// Parameters are the optional corrector parameters defined in tipsy.yml e.g. n for sws-lastn
+type Parameters map[string]string
+
+// Int returns the parameter as an int, or the fallback if it isn't set
+func (parameters Parameters) Int(name string, fallback int) (int, error) {
+	value, ok := parameters[name]
+	if !ok {
+		return fallback, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback, errors.New("parameter " + name + " must be an integer: " + err.Error())
+	}
+
+	return number, nil
+}
+
+// Strings returns the parameter as a comma separated list, or the fallback if it isn't set
+func (parameters Parameters) Strings(name string, fallback []string) []string {
+	value, ok := parameters[name]
+	if !ok {
+		return fallback
+	}
+
+	values := make([]string, 0)
+	for _, field := range strings.Split(value, ",") {
+		if strings.TrimSpace(field) != "" {
+			values = append(values, strings.TrimSpace(field))
+		}
+	}
+
+	return values
+}
+
+// Factory creates a corrector given its parameters
+type Factory func(parameters Parameters) (Corrector, error)
+
+var (
+	registryMutex sync.RWMutex
+	// factories of every corrector that can be used in tipsy.yml
+	factories = make(map[string]Factory)
+	// correctors configured with the parameters from tipsy.yml
+	configured = make(map[string]Corrector)
+)
+
+// Register makes a corrector available by name. Third-party packages should call it from init()
+func Register(name string, factory Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if factory == nil {
+		panic("correctors: Register factory is nil for " + name)
+	}
+	if _, duplicate := factories[name]; duplicate {
+		panic("correctors: Register called twice for " + name)
+	}
+
+	factories[name] = factory
+}
+
+// New creates a new instance of the named corrector with the given parameters
+func New(name string, parameters Parameters) (Corrector, error) {
+	registryMutex.RLock()
+	factory, ok := factories[name]
+	registryMutex.RUnlock()
+
+	if !ok {
+		return nil, errors.New("corrector unknown: " + name)
+	}
+
+	return factory(parameters)
+}
+
+// Configure sets the parameters used by Lookup for the given correctors
+func Configure(parameters map[string]Parameters) error {
+	instances := make(map[string]Corrector, len(parameters))
+
+	for name, params := range parameters {
+		corrector, err := New(name, params)
+		if err != nil {
+			return errors.New("could not configure corrector " + name + ": " + err.Error())
+		}
+		instances[name] = corrector
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for name, corrector := range instances {
+		configured[name] = corrector
+	}
+
+	return nil
+}
+
+// Lookup returns the named corrector, using the parameters set by Configure if any
+func Lookup(name string) (Corrector, error) {
+	registryMutex.RLock()
+	corrector, ok := configured[name]
+	registryMutex.RUnlock()
+
+	if ok {
+		return corrector, nil
+	}
+
+	corrector, err := New(name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	// another goroutine may have configured it in the meantime
+	if existing, ok := configured[name]; ok {
+		return existing, nil
+	}
+	configured[name] = corrector
+
+	return corrector, nil
+}
+
+// LookupWithOptions returns the named corrector adapted to the given options
+func LookupWithOptions(name string, options *Options) (Corrector, error) {
+	corrector, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if adaptive, ok := corrector.(Adaptive); ok && options != nil {
+		return adaptive.Adapt(options), nil
+	}
+
+	return corrector, nil
+}
+
+// Registered returns the sorted names of every registered corrector
+func Registered() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// IsRegistered returns true if a corrector with the given name has been registered
+func IsRegistered(name string) bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	_, ok := factories[name]
+	return ok
+}
+
+// function adapts a correction function and its inverse to the Corrector interface
+type function struct {
+	name    string
+	apply   func(password string) string
+	inverse func(password string) []string
+}
+
+func (f *function) Name() string {
+	return f.name
+}
+
+func (f *function) Apply(password string) string {
+	return f.apply(password)
+}
+
+func (f *function) Inverse(password string) []string {
+	return f.inverse(password)
+}
+
+// layoutFunction adapts a correction function that depends on the keyboard layout to the Corrector interface
+type layoutFunction struct {
+	name    string
+	apply   func(password string, layout *Layout) string
+	inverse func(password string, layout *Layout) []string
+	layout  *Layout
+}
+
+func (f *layoutFunction) Name() string {
+	return f.name
+}
+
+func (f *layoutFunction) Apply(password string) string {
+	return f.apply(password, f.layout)
+}
+
+func (f *layoutFunction) Inverse(password string) []string {
+	return f.inverse(password, f.layout)
+}
+
+func (f *layoutFunction) Adapt(options *Options) Corrector {
+	adapted := *f
+	if options.Layout != nil {
+		adapted.layout = options.Layout
+	}
+	return &adapted
+}
+
+// localeFunction adapts a correction function that depends on the locale to the Corrector interface
+type localeFunction struct {
+	name    string
+	apply   func(password string, locale language.Tag) string
+	inverse func(password string, locale language.Tag) []string
+	locale  language.Tag
+}
+
+func (f *localeFunction) Name() string {
+	return f.name
+}
+
+func (f *localeFunction) Apply(password string) string {
+	return f.apply(password, f.locale)
+}
+
+func (f *localeFunction) Inverse(password string) []string {
+	return f.inverse(password, f.locale)
+}
+
+func (f *localeFunction) Adapt(options *Options) Corrector {
+	adapted := *f
+	if options.Locale != language.Und {
+		adapted.locale = options.Locale
+	}
+	return &adapted
+}
+
+// withoutParameters returns a factory for correctors that can't be configured
+func withoutParameters(corrector Corrector) Factory {
+	return func(parameters Parameters) (Corrector, error) {
+		if len(parameters) != 0 {
+			return nil, errors.New("corrector " + corrector.Name() + " does not take any parameters")
+		}
+		return corrector, nil
+	}
+}
+
+// switchShiftLastN is the sws-lastn corrector, n is set in tipsy.yml and defaults to 2
+type switchShiftLastN struct {
+	n      int
+	layout *Layout
+}
+
+func newSwitchShiftLastN(parameters Parameters) (Corrector, error) {
+	n, err := parameters.Int("n", 2)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 1 {
+		return nil, errors.New("parameter n must be at least 1")
+	}
+
+	return &switchShiftLastN{n: n, layout: USQwerty}, nil
+}
+
+func (c *switchShiftLastN) Name() string {
+	return SwitchLastN
+}
+
+func (c *switchShiftLastN) Apply(password string) string {
+	// we can't switch more characters than there are in the password
+	if utf8.RuneCountInString(password) < c.n {
+		return password
+	}
+	return switchShiftLastNCharacters(password, c.n, c.layout)
+}
+
+func (c *switchShiftLastN) Inverse(password string) []string {
+	return inverseSwitchShiftLastNCharacters(password, c.n, c.layout)
+}
+
+func (c *switchShiftLastN) Adapt(options *Options) Corrector {
+	adapted := *c
+	if options.Layout != nil {
+		adapted.layout = options.Layout
+	}
+	return &adapted
+}
+
+func init() {
+	Register(SwitchAll, withoutParameters(&localeFunction{SwitchAll, switchCaseAll, inverseSwitchCaseAll, language.Und}))
+	Register(RemoveLast, withoutParameters(&layoutFunction{RemoveLast, func(password string, layout *Layout) string {
+		return RemoveLastChar(password)
+	}, inverseRemoveLast, USQwerty}))
+	Register(SwitchFirst, withoutParameters(&localeFunction{SwitchFirst, switchCaseFirstLetter, inverseSwitchCaseFirstLetter, language.Und}))
+	Register(RemoveFirst, withoutParameters(&layoutFunction{RemoveFirst, func(password string, layout *Layout) string {
+		return RemoveFirstChar(password)
+	}, inverseRemoveFirst, USQwerty}))
+	Register(SwitchLast, withoutParameters(&layoutFunction{SwitchLast, switchShiftLastCharacter, inverseSwitchShiftLastCharacter, USQwerty}))
+	Register(SwitchLastN, newSwitchShiftLastN)
+	Register(UpperNCapital, withoutParameters(&localeFunction{UpperNCapital, upperToCapital, inverseUpperToCapital, language.Und}))
+	Register(NumberToSymbolLast, withoutParameters(&layoutFunction{NumberToSymbolLast, convertLastNumberToSymbol, inverseConvertLastNumberToSymbol, USQwerty}))
+	Register(Capital2Upper, withoutParameters(&localeFunction{Capital2Upper, capitalToUpper, inverseCapitalToUpper, language.Und}))
+	Register(FullWidthHalfWidth, withoutParameters(&function{FullWidthHalfWidth, FullWidthToHalfWidth, inverseFullWidthToHalfWidth}))
+	Register(AddOneLast, withoutParameters(&function{AddOneLast, AppendOne, inverseAppendOne}))
+}
@@ -0,0 +1,119 @@
+package correctors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	Register("test-reverse", withoutParameters(&function{"test-reverse", reverse, func(password string) []string {
+		return exactInverse(password, []string{reverse(password)}, reverse)
+	}}))
+}
+
+func reverse(password string) string {
+	runes := []rune(password)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func TestRegister(t *testing.T) {
+	if !IsRegistered("test-reverse") {
+		t.Errorf("should register third-party correctors")
+	}
+
+	ball, err := GetBall("password", []string{SwitchAll, "test-reverse"})
+	assert.Nil(t, err)
+	if !assert.ElementsMatch(t, ball, []string{"PASSWORD", "drowssap"}) {
+		t.Errorf("should build the ball using registered correctors")
+	}
+
+	assert.Panics(t, func() { Register(SwitchAll, withoutParameters(&function{})) }, "should not register a corrector twice")
+}
+
+func TestNew(t *testing.T) {
+	corrector, err := New(SwitchLastN, Parameters{"n": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if corrector.Apply("password123") != "password!@#" {
+		t.Errorf("should use the n parameter")
+	}
+
+	if _, err = New(SwitchLastN, Parameters{"n": "two"}); err == nil {
+		t.Errorf("should reject invalid parameters")
+	}
+
+	if _, err = New(SwitchAll, Parameters{"n": "3"}); err == nil {
+		t.Errorf("should reject parameters for correctors that don't take any")
+	}
+
+	if _, err = New("unknown", nil); err == nil {
+		t.Errorf("should reject unknown correctors")
+	}
+}
+
+func TestApplyCorrectionFunctionUnknown(t *testing.T) {
+	if ApplyCorrectionFunction("unknown", "password") != "password" {
+		t.Errorf("should not correct the password with an unknown corrector")
+	}
+}
+
+func TestGetNBestCorrectors(t *testing.T) {
+	typos := map[string]int{
+		"same":      90234,
+		"other":     1918,
+		"swc-all":   1698,
+		"rm-last":   382,
+		"swc-first": 209,
+	}
+
+	if !assert.Equal(t, GetNBestCorrectors(2, typos), []string{SwitchAll, RemoveLast}) {
+		t.Errorf("should return the n most frequent registered correctors")
+	}
+
+	if !assert.Equal(t, GetNBestCorrectors(10, typos), []string{SwitchAll, RemoveLast, SwitchFirst}) {
+		t.Errorf("should return at most every registered corrector in the typo distribution")
+	}
+}
+
+func TestGetCorrectionsDepth(t *testing.T) {
+	correctors := []string{SwitchAll, RemoveLast}
+
+	corrections, err := GetCorrections("PASSWORD1", correctors, &Options{Depth: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"password1", "PASSWORD", "password", "PASSWOR"}, Passwords(corrections))
+	assert.Contains(t, corrections, Correction{"password", "swc-all+rm-last", []string{SwitchAll, RemoveLast}})
+
+	// depth 1 only applies each corrector once
+	corrections, err = GetCorrections("PASSWORD1", correctors, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"password1", "PASSWORD"}, Passwords(corrections))
+
+	// the corrections with fewer correctors are kept first
+	capped, err := GetCorrections("PASSWORD1", correctors, &Options{Depth: 3, MaxBallSize: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"password1", "PASSWORD", "password"}, Passwords(capped))
+}
+
+func TestGetCorrectionsInvalidPassword(t *testing.T) {
+	_, err := GetCorrections("", []string{SwitchAll}, nil)
+	assert.Equal(t, ErrEmptyPassword, err)
+
+	_, err = GetBall("pass\xffword", []string{SwitchAll})
+	assert.Equal(t, ErrInvalidPassword, err)
+}
+
+func TestGetCorrectionsSkipsPanics(t *testing.T) {
+	panicking := &function{"test-panic", func(password string) string {
+		panic("unexpected password")
+	}, func(password string) []string {
+		return []string{}
+	}}
+
+	assert.Empty(t, applyAll(panicking, "password"), "should skip the corrections of a corrector that panics")
+}
@@ -0,0 +1,117 @@
+package correctors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Position policy constants for the transpose corrector
+const (
+	Anywhere = "anywhere"
+	FirstK   = "first"
+	LastK    = "last"
+)
+
+var positionPolicies = []string{Anywhere, FirstK, LastK}
+
+// transpose is the transpose corrector, it swaps two adjacent characters e.g. psasword => password
+type transpose struct {
+	// position is one of anywhere, first or last
+	position string
+	// k is the number of characters at the start or end of the password where swaps are allowed
+	k int
+}
+
+func newTranspose(parameters Parameters) (Corrector, error) {
+	position := Anywhere
+	if value, ok := parameters["position"]; ok {
+		position = value
+	}
+
+	if !StringInSlice(position, positionPolicies) {
+		return nil, errors.New("unknown position " + position + " - use one of: " + strings.Join(positionPolicies, ", "))
+	}
+
+	k, err := parameters.Int("k", 2)
+	if err != nil {
+		return nil, err
+	}
+
+	if k < 2 {
+		return nil, errors.New("parameter k must be at least 2")
+	}
+
+	return &transpose{position: position, k: k}, nil
+}
+
+func (c *transpose) Name() string {
+	return Transpose
+}
+
+func (c *transpose) Apply(password string) string {
+	corrections := c.ApplyAll(password)
+	if len(corrections) == 0 {
+		return password
+	}
+	return corrections[0]
+}
+
+// ApplyAll returns the passwords with two adjacent characters swapped, from left to right
+func (c *transpose) ApplyAll(password string) []string {
+	runes := []rune(password)
+	start, end := 0, len(runes)-1
+
+	switch c.position {
+	case FirstK:
+		end = min(end, c.k-1)
+	case LastK:
+		start = max(start, len(runes)-c.k)
+	}
+
+	return TransposeAdjacent(password, start, end)
+}
+
+// Inverse returns the passwords for which the corrector returns the given password
+func (c *transpose) Inverse(password string) []string {
+	// swapping the same characters again undoes the typo
+	return c.ApplyAll(password)
+}
+
+// TransposeAdjacent returns every password obtained by swapping the characters at index i and i+1, for start <= i < end
+// transpose
+func TransposeAdjacent(password string, start int, end int) []string {
+	runes := []rune(password)
+	transpositions := make([]string, 0)
+
+	for i := start; i < end && i+1 < len(runes); i++ {
+		// swapping identical characters doesn't change the password
+		if runes[i] == runes[i+1] {
+			continue
+		}
+
+		swapped := make([]rune, len(runes))
+		copy(swapped, runes)
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+		transpositions = append(transpositions, string(swapped))
+	}
+
+	return transpositions
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func init() {
+	Register(Transpose, newTranspose)
+}
@@ -0,0 +1,56 @@
+package correctors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransposeAdjacent(t *testing.T) {
+	if !assert.Equal(t, []string{"apss", "psas"}, TransposeAdjacent("pass", 0, 3)) {
+		t.Errorf("should swap every pair of different adjacent characters")
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	corrector, err := Lookup(Transpose)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !assert.Contains(t, corrector.(MultiCorrector).ApplyAll("psasword"), "password") {
+		t.Errorf("should correct swapped characters anywhere by default")
+	}
+
+	corrector, err = New(Transpose, Parameters{"position": "first", "k": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Equal(t, []string{"apssword", "psasword"}, corrector.(MultiCorrector).ApplyAll("password")) {
+		t.Errorf("should only swap characters in the first k characters")
+	}
+
+	corrector, err = New(Transpose, Parameters{"position": "last", "k": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Equal(t, []string{"passwodr"}, corrector.(MultiCorrector).ApplyAll("password")) {
+		t.Errorf("should only swap characters in the last k characters")
+	}
+
+	for _, typo := range corrector.Inverse("password") {
+		if !assert.Contains(t, corrector.(MultiCorrector).ApplyAll(typo), "password") {
+			t.Errorf("should only return typos that are corrected to the password")
+		}
+	}
+
+	if _, err = New(Transpose, Parameters{"position": "middle"}); err == nil {
+		t.Errorf("should reject unknown position policies")
+	}
+
+	ball, err := GetBallWithCorrectionType("ab", []string{Transpose})
+	assert.Nil(t, err)
+	if ball["ba"] != Transpose {
+		t.Errorf("should report the transpose correction type")
+	}
+}
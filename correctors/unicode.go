@@ -0,0 +1,152 @@
+package correctors
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// Normalisation constants
+const (
+	NFC             = "NFC"
+	NFKC            = "NFKC"
+	NoNormalisation = "none"
+)
+
+// Normalise applies the unicode normalisation form to the password so that it is encoded the same way on every OS
+func Normalise(password string, form string) (string, error) {
+	switch form {
+	case NFC:
+		return norm.NFC.String(password), nil
+	case NFKC:
+		return norm.NFKC.String(password), nil
+	case NoNormalisation, "":
+		return password, nil
+	}
+
+	return password, errors.New("unknown unicode normalisation " + form + " - use one of: NFC, NFKC, none")
+}
+
+// switchCase returns the character with the opposite case using the case mapping of the locale e.g. i => İ in Turkish
+func switchCase(character rune, locale language.Tag) string {
+	if unicode.IsLower(character) {
+		return cases.Upper(locale).String(string(character))
+	} else if unicode.IsUpper(character) {
+		return cases.Lower(locale).String(string(character))
+	}
+	return string(character)
+}
+
+func switchCaseAll(password string, locale language.Tag) string {
+	newPassword := ""
+	for _, value := range password {
+		newPassword = newPassword + switchCase(value, locale)
+	}
+	return newPassword
+}
+
+func switchCaseFirstLetter(password string, locale language.Tag) string {
+	// we avoid toTitle in case the password contains a space
+	value, size := utf8.DecodeRuneInString(password)
+	if size == 0 {
+		// empty passwords are rejected by GetCorrections
+		return ""
+	}
+
+	return switchCase(value, locale) + password[size:]
+}
+
+// inverseSwitchCaseAll returns the passwords whose letters all have the opposite case
+func inverseSwitchCaseAll(password string, locale language.Tag) []string {
+	typos := runeInverse(password, caseCandidates(password, locale), func(character rune) string {
+		return switchCase(character, locale)
+	})
+
+	return exactInverse(password, typos, func(typo string) string {
+		return switchCaseAll(typo, locale)
+	})
+}
+
+// inverseSwitchCaseFirstLetter returns the passwords whose first letter has the opposite case
+func inverseSwitchCaseFirstLetter(password string, locale language.Tag) []string {
+	typos := make([]string, 0)
+	for _, candidate := range caseCandidates(password, locale) {
+		switched := switchCase(candidate, locale)
+		if strings.HasPrefix(password, switched) {
+			typos = append(typos, string(candidate)+password[len(switched):])
+		}
+	}
+
+	return exactInverse(password, typos, func(typo string) string {
+		return switchCaseFirstLetter(typo, locale)
+	})
+}
+
+// capitalToUpper only corrects capitalised passwords, the letters that don't start a word must be lower case
+func capitalToUpper(password string, locale language.Tag) string {
+	if cases.Title(locale).String(password) == password {
+		return cases.Upper(locale).String(password)
+	}
+	return password
+}
+
+func upperToCapital(password string, locale language.Tag) string {
+	if cases.Upper(locale).String(password) == password {
+		return cases.Title(locale).String(password)
+	}
+	return password
+}
+
+// inverseCapitalToUpper returns the capitalised passwords that are the password once upper cased. Only the first
+// letter of each word of a capitalised password is upper case, so the tail is lower cased and every case of the first
+// letter is tried e.g. PASSWORD <= Password
+func inverseCapitalToUpper(password string, locale language.Tag) []string {
+	upper := cases.Upper(locale)
+	lower := cases.Lower(locale)
+
+	typos := []string{cases.Title(locale).String(lower.String(password))}
+	for _, candidate := range caseCandidates(password, locale) {
+		prefix := upper.String(string(candidate))
+		if prefix != "" && strings.HasPrefix(password, prefix) {
+			typos = append(typos, string(candidate)+lower.String(password[len(prefix):]))
+		}
+	}
+
+	return exactInverse(password, typos, func(typo string) string {
+		return capitalToUpper(typo, locale)
+	})
+}
+
+// inverseUpperToCapital returns the upper case password if it's capitalised to the password
+func inverseUpperToCapital(password string, locale language.Tag) []string {
+	return exactInverse(password, []string{cases.Upper(locale).String(password)}, func(typo string) string {
+		return upperToCapital(typo, locale)
+	})
+}
+
+// FullWidthToHalfWidth converts full-width characters typed with an IME to their half-width form e.g. ｐａｓｓ => pass
+// fw2hw
+func FullWidthToHalfWidth(password string) string {
+	return width.Narrow.String(password)
+}
+
+// HalfWidthToFullWidth converts half-width characters to their full-width form e.g. pass => ｐａｓｓ
+func HalfWidthToFullWidth(password string) string {
+	return width.Widen.String(password)
+}
+
+// inverseFullWidthToHalfWidth returns the passwords with any of their characters typed in full-width e.g. ｐass => pass
+func inverseFullWidthToHalfWidth(password string) []string {
+	candidates := []rune(password + HalfWidthToFullWidth(password))
+	typos := runeInverse(password, candidates, func(character rune) string {
+		return FullWidthToHalfWidth(string(character))
+	})
+
+	return exactInverse(password, typos, FullWidthToHalfWidth)
+}
@@ -0,0 +1,64 @@
+package correctors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestNormalise(t *testing.T) {
+	// é typed as a single code point on one OS and as e + combining accent on another
+	composed := "caf\u00e9"
+	decomposed := "cafe\u0301"
+
+	nfc, err := Normalise(decomposed, NFC)
+	assert.Nil(t, err)
+	assert.Equal(t, composed, nfc)
+
+	nfkc, err := Normalise("ｐａｓｓ", NFKC)
+	assert.Nil(t, err)
+	assert.Equal(t, "pass", nfkc)
+
+	none, err := Normalise(decomposed, NoNormalisation)
+	assert.Nil(t, err)
+	assert.Equal(t, decomposed, none)
+
+	_, err = Normalise("password", "NFD")
+	assert.NotNil(t, err)
+}
+
+func TestSwitchCaseLocale(t *testing.T) {
+	turkish := language.Turkish
+
+	assert.Equal(t, "İSTANBUL", switchCaseAll("istanbul", turkish))
+	assert.Equal(t, "ıstanbul", switchCaseAll("ISTANBUL", turkish))
+	assert.Equal(t, "İstanbul", switchCaseFirstLetter("istanbul", turkish))
+	assert.Equal(t, "ISTANBUL", switchCaseAll("istanbul", language.Und))
+
+	// ß has no single upper case character
+	assert.Equal(t, "STRASSE", switchCaseAll("straße", language.German))
+}
+
+func TestLocaleCorrector(t *testing.T) {
+	corrector, err := LookupWithOptions(SwitchAll, &Options{Locale: language.Turkish})
+	assert.Nil(t, err)
+	assert.Equal(t, "İSTANBUL", corrector.Apply("istanbul"))
+
+	corrector, err = Lookup(SwitchAll)
+	assert.Nil(t, err)
+	assert.Equal(t, "ISTANBUL", corrector.Apply("istanbul"))
+}
+
+func TestFullWidthToHalfWidth(t *testing.T) {
+	assert.Equal(t, "password1!", FullWidthToHalfWidth("ｐａｓｓｗｏｒｄ１！"))
+	assert.Equal(t, "password", FullWidthToHalfWidth("password"))
+	assert.Equal(t, "ｐａｓｓ", HalfWidthToFullWidth("pass"))
+
+	corrector, err := Lookup(FullWidthHalfWidth)
+	assert.Nil(t, err)
+	// any of the characters can be typed in full-width
+	inverse := corrector.Inverse("pass")
+	assert.Len(t, inverse, 15)
+	assert.Subset(t, inverse, []string{"ｐａｓｓ", "ｐass", "paｓs"})
+}
@@ -0,0 +1,92 @@
+package correctors
+
+import (
+	"strings"
+	"unicode"
+)
+
+// RemoveLeadingWhitespace removes the whitespace typed or pasted before the password e.g. " password" => password
+// rm-leading-ws
+func RemoveLeadingWhitespace(password string) string {
+	return strings.TrimLeftFunc(password, unicode.IsSpace)
+}
+
+// RemoveTrailingWhitespace removes the whitespace typed or pasted after the password e.g. "password " => password
+// rm-trailing-ws
+func RemoveTrailingWhitespace(password string) string {
+	return strings.TrimRightFunc(password, unicode.IsSpace)
+}
+
+// CollapseWhitespace replaces the runs of whitespace inside the password with their first character e.g. "correct  horse" => "correct horse"
+// leading and trailing whitespace is left to rm-leading-ws and rm-trailing-ws
+// collapse-ws
+func CollapseWhitespace(password string) string {
+	runes := []rune(password)
+	start, end := whitespaceBounds(runes)
+
+	collapsed := make([]rune, 0, len(runes))
+	collapsed = append(collapsed, runes[:start]...)
+	for i := start; i < end; i++ {
+		if unicode.IsSpace(runes[i]) && unicode.IsSpace(runes[i-1]) {
+			continue
+		}
+		collapsed = append(collapsed, runes[i])
+	}
+	collapsed = append(collapsed, runes[end:]...)
+
+	return string(collapsed)
+}
+
+// inverseRemoveLeadingWhitespace returns the password typed with a stray whitespace character before it
+func inverseRemoveLeadingWhitespace(password string) []string {
+	typos := make([]string, 0, len(typedWhitespace))
+	for _, whitespace := range typedWhitespace {
+		typos = append(typos, string(whitespace)+password)
+	}
+	return exactInverse(password, typos, RemoveLeadingWhitespace)
+}
+
+// inverseRemoveTrailingWhitespace returns the password typed with a stray whitespace character after it
+func inverseRemoveTrailingWhitespace(password string) []string {
+	typos := make([]string, 0, len(typedWhitespace))
+	for _, whitespace := range typedWhitespace {
+		typos = append(typos, password+string(whitespace))
+	}
+	return exactInverse(password, typos, RemoveTrailingWhitespace)
+}
+
+// inverseCollapseWhitespace returns the passwords typed with a stray whitespace character after one of the whitespace
+// characters inside the password
+func inverseCollapseWhitespace(password string) []string {
+	typos := make([]string, 0)
+
+	runes := []rune(password)
+	start, end := whitespaceBounds(runes)
+	for i := start; i < end; i++ {
+		if unicode.IsSpace(runes[i]) {
+			for _, whitespace := range typedWhitespace {
+				typos = append(typos, string(runes[:i+1])+string(whitespace)+string(runes[i+1:]))
+			}
+		}
+	}
+
+	return exactInverse(password, typos, CollapseWhitespace)
+}
+
+// whitespaceBounds returns the index of the first and one past the last character that isn't whitespace
+func whitespaceBounds(runes []rune) (int, int) {
+	start, end := 0, len(runes)
+	for start < end && unicode.IsSpace(runes[start]) {
+		start++
+	}
+	for end > start && unicode.IsSpace(runes[end-1]) {
+		end--
+	}
+	return start, end
+}
+
+func init() {
+	Register(RemoveLeadingWS, withoutParameters(&function{RemoveLeadingWS, RemoveLeadingWhitespace, inverseRemoveLeadingWhitespace}))
+	Register(RemoveTrailingWS, withoutParameters(&function{RemoveTrailingWS, RemoveTrailingWhitespace, inverseRemoveTrailingWhitespace}))
+	Register(CollapseWS, withoutParameters(&function{CollapseWS, CollapseWhitespace, inverseCollapseWhitespace}))
+}
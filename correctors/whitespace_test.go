@@ -0,0 +1,33 @@
+package correctors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveLeadingWhitespace(t *testing.T) {
+	assert.Equal(t, "password", RemoveLeadingWhitespace(" password"))
+	assert.Equal(t, "pass word ", RemoveLeadingWhitespace("\t pass word "))
+	assert.Equal(t, "password", RemoveLeadingWhitespace("password"))
+	assert.Equal(t, []string{" password", "\tpassword", "\u00a0password", "\u3000password"}, inverseRemoveLeadingWhitespace("password"))
+	assert.Empty(t, inverseRemoveLeadingWhitespace(" password"))
+}
+
+func TestRemoveTrailingWhitespace(t *testing.T) {
+	assert.Equal(t, "password", RemoveTrailingWhitespace("password \n"))
+	assert.Equal(t, " pass word", RemoveTrailingWhitespace(" pass word "))
+	assert.Contains(t, inverseRemoveTrailingWhitespace("password"), "password ")
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	assert.Equal(t, "correct horse battery", CollapseWhitespace("correct  horse   battery"))
+	assert.Equal(t, " correct horse  ", CollapseWhitespace(" correct  horse  "))
+	assert.Equal(t, "password", CollapseWhitespace("password"))
+
+	inverse := inverseCollapseWhitespace("correct horse battery")
+	assert.Subset(t, inverse, []string{"correct  horse battery", "correct horse  battery", "correct \thorse battery"})
+	for _, typo := range inverse {
+		assert.Equal(t, "correct horse battery", CollapseWhitespace(typo))
+	}
+}
@@ -0,0 +1,122 @@
+package correctors
+
+import (
+	"errors"
+	"strings"
+)
+
+// layoutPair is a layout the password was typed on by mistake and the layout the user meant to type it on
+type layoutPair struct {
+	from *Layout
+	to   *Layout
+}
+
+// wrongLayout is the wrong-layout corrector, it maps a password typed with the wrong input language back to the
+// same keystrokes on the intended layout e.g. зфыыцщкв typed on ru => password on us
+type wrongLayout struct {
+	pairs []layoutPair
+}
+
+func newWrongLayout(parameters Parameters) (Corrector, error) {
+	pairs := make([]layoutPair, 0)
+
+	for _, value := range parameters.Strings("pairs", []string{Russian + ":" + US, Greek + ":" + US}) {
+		names := strings.Split(value, ":")
+		if len(names) != 2 {
+			return nil, errors.New("layout pair " + value + " must be of the form from:to e.g. ru:us")
+		}
+
+		from, err := GetLayout(strings.TrimSpace(names[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := GetLayout(strings.TrimSpace(names[1]))
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, layoutPair{from, to})
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("parameter pairs must contain at least one layout pair")
+	}
+
+	return &wrongLayout{pairs: pairs}, nil
+}
+
+func (c *wrongLayout) Name() string {
+	return WrongLayout
+}
+
+func (c *wrongLayout) Apply(password string) string {
+	corrections := c.ApplyAll(password)
+	if len(corrections) == 0 {
+		return password
+	}
+	return corrections[0]
+}
+
+// ApplyAll returns the password retyped on the intended layout, for every pair the password could have been typed on
+func (c *wrongLayout) ApplyAll(password string) []string {
+	corrections := make([]string, 0)
+
+	for _, pair := range c.pairs {
+		correction, ok := Retype(password, pair.from, pair.to)
+		if ok && correction != password && !StringInSlice(correction, corrections) {
+			corrections = append(corrections, correction)
+		}
+	}
+
+	return corrections
+}
+
+// Inverse returns the passwords for which the corrector returns the given password
+func (c *wrongLayout) Inverse(password string) []string {
+	typos := make([]string, 0)
+
+	for _, pair := range c.pairs {
+		// every character of the typo is typed on the wrong layout, some layouts type the same character on several keys
+		typos = append(typos, runeInverse(password, pair.from.Runes(), func(character rune) string {
+			retyped, _ := Retype(string(character), pair.from, pair.to)
+			return retyped
+		})...)
+	}
+
+	inverse := make([]string, 0)
+	for _, typo := range typos {
+		if typo != password && !StringInSlice(typo, inverse) && StringInSlice(password, c.ApplyAll(typo)) {
+			inverse = append(inverse, typo)
+		}
+	}
+
+	return inverse
+}
+
+// Retype returns the password typed with the same keystrokes on another layout.
+// It returns false if a character can't be typed on the first layout or if the password is the same on both layouts
+// wrong-layout
+func Retype(password string, from *Layout, to *Layout) (string, bool) {
+	runes := []rune(password)
+	retyped := false
+
+	for index, character := range runes {
+		position, ok := from.Position(character)
+		if !ok {
+			return password, false
+		}
+
+		other, ok := to.At(position)
+		if ok && other != character {
+			runes[index] = other
+			retyped = true
+		}
+	}
+
+	return string(runes), retyped
+}
+
+func init() {
+	Register(WrongLayout, newWrongLayout)
+}
@@ -0,0 +1,43 @@
+package correctors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetype(t *testing.T) {
+	retyped, ok := Retype("зфыыцщкв", RussianJcuken, USQwerty)
+	assert.True(t, ok)
+	assert.Equal(t, "password", retyped)
+
+	retyped, ok = Retype("Πασσςορδ1!", GreekQwerty, USQwerty)
+	assert.True(t, ok)
+	assert.Equal(t, "Password1!", retyped)
+
+	// latin passwords can't be typed on the russian layout
+	_, ok = Retype("password", RussianJcuken, USQwerty)
+	assert.False(t, ok)
+
+	_, ok = Retype("123456", RussianJcuken, USQwerty)
+	assert.False(t, ok)
+}
+
+func TestWrongLayout(t *testing.T) {
+	corrector, err := New(WrongLayout, Parameters{})
+	assert.Nil(t, err)
+	assert.Equal(t, "password", corrector.Apply("зфыыцщкв"))
+	assert.Equal(t, "password", corrector.Apply("πασσςορδ"))
+	assert.Equal(t, "password", corrector.Apply("password"))
+	assert.Equal(t, []string{"зфыыцщкв", "πασσςορδ"}, corrector.Inverse("password"))
+
+	corrector, err = New(WrongLayout, Parameters{"pairs": "us:ru"})
+	assert.Nil(t, err)
+	assert.Equal(t, "зфыыцщкв", corrector.Apply("password"))
+
+	_, err = New(WrongLayout, Parameters{"pairs": "ru"})
+	assert.NotNil(t, err)
+
+	_, err = New(WrongLayout, Parameters{"pairs": "ru:unknown"})
+	assert.NotNil(t, err)
+}
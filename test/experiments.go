@@ -30,7 +30,7 @@ type Result struct {
 func greedyMaxCoverageHeap(config *config.Server, q int, ballSize int, attackerListFile, defenderListFile string) *Result {
 
 	// init checker
-	checker := checkers.NewChecker(config.Typos, config.Correctors)
+	checker := checkers.NewService(config.Typos, config.Correctors)
 
 	// sample from password leaks
 	now := time.Now()
@@ -48,13 +48,14 @@ func greedyMaxCoverageHeap(config *config.Server, q int, ballSize int, attackerL
 		sortedAttackerList     = correctors.ConvertMapToSortedSlice(attackerList)
 		defenderListIndex  int = 0
 		blacklist          []string
-		optimal            map[string]int
+		optimal            checkers.FrequencyList
+		attackerModel      = frequencyList(config, attackerList)
 	)
 
 	if config.Checker.Blacklist != nil {
 		blacklist = checkers.LoadBlacklist(config.Checker.Blacklist.File)
 	} else if config.Checker.Optimal != nil {
-		optimal = checkers.LoadFrequencyBlacklist(config.Checker.Optimal.File, config.MinPasswordLength)
+		optimal = frequencyList(config, checkers.LoadFrequencyBlacklist(config.Checker.Optimal.File, config.MinPasswordLength))
 	}
 
 	for len(guessList) < q {
@@ -87,13 +88,13 @@ func greedyMaxCoverageHeap(config *config.Server, q int, ballSize int, attackerL
 				// printPriorityQueue(&priorityQueue, item.value, "rockyouG")
 
 				_, inSlice := done[item.value]
-				for float64(item.weight) > float64(ballSize)*checkers.PasswordProbability(registeredPassword, attackerList) && len(guessList) < q && !inSlice {
+				for float64(item.weight) > float64(ballSize)*attackerModel.Probability(registeredPassword) && len(guessList) < q && !inSlice {
 					// add guess to guess list
 					log.Println("Guess", len(guessList), "/", q, "password:", item.value, "weight:", float64(item.weight))
 					guessList = append(guessList, item.value)
 
 					// add password & ball to done
-					killed := unionBallNotDone(item.value, done, config, checker, attackerList, blacklist)
+					killed := unionBallNotDone(item.value, done, config, checker, attackerModel, blacklist)
 					for _, password := range killed {
 						done[password] = true
 					}
@@ -104,7 +105,7 @@ func greedyMaxCoverageHeap(config *config.Server, q int, ballSize int, attackerL
 
 					// add all neighbours of this password to the priority queue
 					for _, password := range killed {
-						probability := checkers.PasswordProbability(registeredPassword, attackerList)
+						probability := attackerModel.Probability(registeredPassword)
 						neighbours := getNeighbours(password, config.Correctors, config, checker, attackerList, blacklist)
 						neighbours = append(neighbours, password)
 						for _, neighbour := range neighbours {
@@ -149,7 +150,7 @@ func greedyMaxCoverageHeap(config *config.Server, q int, ballSize int, attackerL
 
 				// don't add neighbour if it's already in the priority queue or if it has already been processed
 				if priorityQueue.Find(neighbour) == nil && !ok {
-					weight := power(neighbour, attackerList, done, config, checker, blacklist, optimal)
+					weight := power(neighbour, attackerModel, done, config, checker, blacklist, optimal)
 					item := &Item{
 						value:  neighbour,
 						weight: -weight,
@@ -172,7 +173,7 @@ func greedyMaxCoverageHeap(config *config.Server, q int, ballSize int, attackerL
 
 	guessListBall := make([]string, 0)
 	for _, password := range guessList {
-		union := unionBall(password, config, checker, attackerList, blacklist)
+		union := unionBall(password, config, checker, attackerModel, blacklist)
 		guessListBall = append(guessListBall, union...)
 		guessListBall = append(guessListBall, password)
 	}
@@ -206,7 +207,10 @@ func guessListBall(guessList []string, corrections []string) []string {
 	for _, password := range guessList {
 		// get ball of passwords in guess list
 		// ball := unionBall(password, config, checker, attackerList, q, blacklist)
-		ball := correctors.GetBall(password, corrections)
+		ball, err := correctors.GetBall(password, corrections)
+		if err != nil {
+			log.Println(err)
+		}
 		ball = append(ball, password)
 		guessListBall = append(guessListBall, ball...)
 	}
@@ -233,6 +237,24 @@ func printPriorityQueue(pq *PriorityQueue, password string, match string) {
 	}
 }
 
+// frequencyList returns the frequency list smoothed as in the optimal checker config, so that the attacker also gives
+// a probability to the passwords that aren't in the list
+func frequencyList(config *config.Server, frequencies map[string]int) checkers.FrequencyList {
+	list := checkers.NewFrequencyList(frequencies)
+	if config.Checker.Optimal == nil || config.Checker.Optimal.Smoothing == nil {
+		return list
+	}
+
+	smoothed, err := checkers.NewSmoothedFrequencyList(list, config.Checker.Optimal.Smoothing.Type, config.Checker.Optimal.Smoothing.Alpha)
+	if err != nil {
+		log.Println(err)
+		return list
+	}
+	return smoothed
+}
+
+// ballProbability is the empirical probability of the ball in the defender's list, the passwords that aren't in the
+// list aren't guessed
 func ballProbability(ball Ball, frequencies map[string]int) float64 {
 	ballProbability := 0.0
 	for _, password := range ball {
@@ -242,20 +264,25 @@ func ballProbability(ball Ball, frequencies map[string]int) float64 {
 }
 
 // returns the union ball of passwords for checking
-func unionBall(password string, config *config.Server, checker *checkers.Checker, optimal map[string]int, blacklist []string) []string {
+func unionBall(password string, config *config.Server, checker *checkers.Service, optimal checkers.FrequencyList, blacklist []string) []string {
 	// TODO make get ball configurable according to the checker
 	unionBall := make([]string, 0)
+	var err error
 
 	// TODO add support for typtop
 	if config.Checker.Always {
-		unionBall = checker.CheckAlways(password)
+		unionBall, err = checker.CheckAlways(password)
 		// log.Println("always")
 	} else if config.Checker.Blacklist != nil {
 		// log.Println("blacklist")
-		unionBall = checker.CheckBlacklist(password, blacklist)
+		unionBall, err = checker.CheckBlacklist(password, blacklist)
 	} else if config.Checker.Optimal != nil {
 		// log.Println("optimal")
-		unionBall = checker.CheckOptimal(password, optimal, config.Checker.Optimal.QthMostProbablePassword)
+		unionBall, err = checker.CheckOptimalList(password, optimal, optimal, config.Checker.Optimal.QthMostProbablePassword)
+	}
+
+	if err != nil {
+		log.Println(err)
 	}
 
 	// check if passwords are in done
@@ -263,7 +290,7 @@ func unionBall(password string, config *config.Server, checker *checkers.Checker
 }
 
 // returns the union ball of passwords that are not in the done list
-func unionBallNotDone(password string, done map[string]bool, config *config.Server, checker *checkers.Checker, optimal map[string]int, blacklist []string) []string {
+func unionBallNotDone(password string, done map[string]bool, config *config.Server, checker *checkers.Service, optimal checkers.FrequencyList, blacklist []string) []string {
 	// TODO make get ball configurable according to the checker
 	unionBallNotDone := make([]string, 0)
 	temp := unionBall(password, config, checker, optimal, blacklist)
@@ -282,7 +309,7 @@ func unionBallNotDone(password string, done map[string]bool, config *config.Serv
 }
 
 // TODO fix power function
-func power(password string, attackerList map[string]int, done map[string]bool, config *config.Server, checker *checkers.Checker, blacklist []string, optimal map[string]int) float64 {
+func power(password string, attackerModel checkers.FrequencyList, done map[string]bool, config *config.Server, checker *checkers.Service, blacklist []string, optimal checkers.FrequencyList) float64 {
 	probability := 0.0
 
 	// add passwords in ball to done
@@ -290,7 +317,7 @@ func power(password string, attackerList map[string]int, done map[string]bool, c
 	// fmt.Println("unionball not done", unionBall)
 
 	for _, pw := range unionBall {
-		probability += checkers.PasswordProbability(pw, attackerList)
+		probability += attackerModel.Probability(pw)
 	}
 	return probability
 }
@@ -304,30 +331,32 @@ func remove(slice []string, s string) []string {
 	return nil
 }
 
-func getNeighbours(password string, bestCorrectors []string, conf *config.Server, checker *checkers.Checker, attackerList map[string]int, blacklist []string) []string {
+func getNeighbours(password string, bestCorrectors []string, conf *config.Server, checker *checkers.Service, attackerList map[string]int, blacklist []string) []string {
 	neighbours := make([]string, 0)
-	for _, corrector := range bestCorrectors {
-		edits := make([]string, 0)
-
-		edits = correctors.ApplyInverseCorrectionFunction(corrector, password)
-		neighbours = append(neighbours, edits...)
+	for _, name := range bestCorrectors {
+		// every password that the corrector corrects to the password
+		corrector, err := correctors.LookupWithOptions(name, checker.Options)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		neighbours = append(neighbours, corrector.Inverse(password)...)
 	}
-	neighbours = correctors.DeleteEmpty(neighbours)
 	return neighbours
 }
 
 func applyEdits(password string) []string {
-	edits := make([]string, 10)
+	runes := []rune(password)
+	edits := make([]string, 0)
 
-	for _, letter := range correctors.LetterRunes {
-		for i := 0; i < len(password); i++ {
+	for _, letter := range append([]rune{' '}, correctors.USQwerty.Runes()...) {
+		for i := 0; i <= len(runes); i++ {
 			// add every rune in every index
-			edits = append(edits, password[:i]+string(letter)+password[i:])
+			edits = append(edits, string(runes[:i])+string(letter)+string(runes[i:]))
 		}
-		edits = append(edits, password+string(letter))
 	}
-	for i := 0; i < len(password); i++ {
-		edits = append(edits, password[:i]+password[i+1:])
+	for i := 0; i < len(runes); i++ {
+		edits = append(edits, string(runes[:i])+string(runes[i+1:]))
 	}
 	return edits
 }
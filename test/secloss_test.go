@@ -219,12 +219,12 @@ func TestSecLoss(t *testing.T) {
 		naiveGuesses := result.NaiveGuessList[:rateLimit]
 
 		// init checker
-		checker := checkers.NewChecker(server.Typos, server.Correctors)
+		checker := checkers.NewService(server.Typos, server.Correctors)
 
 		guessListBall := make([]string, 0)
 		for _, password := range guesses {
 
-			union := unionBall(password, server, checker, defenderList, blacklist)
+			union := unionBall(password, server, checker, frequencyList(server, defenderList), blacklist)
 			guessListBall = append(guessListBall, union...)
 			guessListBall = append(guessListBall, password)
 		}
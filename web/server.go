@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/ppartarr/tipsy/checkers"
-	"github.com/ppartarr/tipsy/checkers/typtop"
 	"github.com/ppartarr/tipsy/web/session"
 	"github.com/ppartarr/tipsy/web/users"
 )
@@ -41,10 +40,9 @@ var (
 
 // Server handles HTTP traffic from client
 type Server struct {
-	FileHandler   *FileServer
-	UserService   *users.UserService
-	Checker       *checkers.Checker
-	TypTopChecker *typtop.Checker
+	FileHandler *FileServer
+	UserService *users.UserService
+	Checker     checkers.Checker
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -1,15 +1,16 @@
 package users
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/mcnijman/go-emailaddress"
 	"github.com/ppartarr/tipsy/checkers"
 	"github.com/ppartarr/tipsy/checkers/typtop"
+	"github.com/ppartarr/tipsy/correctors"
 	"github.com/ppartarr/tipsy/web/session"
 )
 
@@ -19,14 +20,21 @@ type LoginForm struct {
 	Password string
 	Pasted   string
 	NoJS     string
+	Layout   string
 	Errors   map[string]string
 }
 
 // Validate checks that the fields in the login form are set
-func (form *LoginForm) Validate() bool {
+func (form *LoginForm) Validate(maxPasswordLength int) bool {
 	form.Errors = make(map[string]string)
 
-	if strings.TrimSpace(form.Password) == "" {
+	// whitespace is part of the password, only reject empty passwords
+	if form.Password == "" {
+		form.Errors["Login"] = "Username and password incorrect"
+	}
+
+	// no registered password is longer than the maximum, and the typtop wait list can't encrypt longer passwords
+	if len(form.Password) > maxPasswordLength {
 		form.Errors["Login"] = "Username and password incorrect"
 	}
 
@@ -43,6 +51,28 @@ func (form *LoginForm) Validate() bool {
 	return len(form.Errors) == 0
 }
 
+// verifyPassword verifies the normalised password, then the password as submitted if it doesn't match and the
+// normalisation changed it, so that the users whose hash was made before the normalisation can still login e.g. with a
+// password typed on macOS. Typtop users are only verified once, every login updates their state
+func verifyPassword(ctx context.Context, checker checkers.Checker, submittedPassword string, normalisedPassword string, credential *checkers.Credential) (*checkers.Result, error) {
+	result, err := checker.Verify(ctx, normalisedPassword, credential)
+	if err != nil || result.Match || submittedPassword == normalisedPassword || credential.State != nil {
+		return result, err
+	}
+
+	submitted, err := checker.Verify(ctx, submittedPassword, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	if submitted.Match {
+		log.Println("verified the password as submitted, its hash isn't of the normalised password")
+		return submitted, nil
+	}
+
+	return result, nil
+}
+
 // Login allows a user to login to their account
 func (userService *UserService) Login(w http.ResponseWriter, r *http.Request) (form *LoginForm, err error) {
 	// TODO handle HEAD, PUT, and PATCH separately
@@ -56,127 +86,120 @@ func (userService *UserService) Login(w http.ResponseWriter, r *http.Request) (f
 	log.Println(r.Form)
 	form = &LoginForm{
 		Email:    r.PostFormValue("email"),
-		Password: r.PostFormValue("password"),
+		Password: userService.normalise(r.PostFormValue("password")),
 		Pasted:   r.PostFormValue("pasted"),
 		NoJS:     r.PostFormValue("nojs"),
+		Layout:   r.PostFormValue("layout"),
 	}
-	if form.Validate() == false {
+	if form.Validate(userService.config.MaxPasswordLength) == false {
 		log.Println(form.Errors)
 		return form, errors.New("you must submit a valid form")
 	}
 
-	if userService.config.Checker.TypTop == nil {
+	// get the credential of the user, typtop users are stored separately
+	var user *User
+	var typtopUser *typtop.User
+	var credential *checkers.Credential
+	var registeredLayout string
 
-		// get user from email
-		user, err := userService.getUser(form.Email)
+	if userService.config.Checker.TypTop == nil {
+		user, err = userService.getUser(form.Email)
 		if err != nil {
 			log.Println("could not get user " + form.Email + ": " + err.Error())
 			form.Errors["Login"] = "Username and password incorrect"
 			return form, errors.New("you must submit a valid form")
 		}
+		credential = &checkers.Credential{PasswordHash: user.PasswordHash}
+		registeredLayout = user.Layout
 
-		// use one of always, blacklist, optimal
-		if userService.config.Checker.Always {
-			log.Println("using always checker")
-			// check password
-			ball := userService.checker.CheckAlways(form.Password)
-			if !checkPasswordAndBall(form.Password, ball, user.PasswordHash) {
-
-				// increment login attempts
-				err = userService.checkLoginAttempts(w, r, user)
-				if err != nil {
-					return form, errors.New("failed to check login attemps")
-				}
-			}
-		} else if userService.config.Checker.Blacklist != nil {
-			log.Println("using blacklist checker")
-			blacklist := checkers.LoadBlacklist(userService.config.Checker.Blacklist.File)
-			ball := userService.checker.CheckBlacklist(form.Password, blacklist)
-
-			// if password check fails, increment login attempts
-			if !checkPasswordAndBall(form.Password, ball, user.PasswordHash) {
-
-				// increment login attempts
-				err = userService.checkLoginAttempts(w, r, user)
-				if err != nil {
-					return form, errors.New("failed to check login attemps")
-				}
-			}
-		} else if userService.config.Checker.Optimal != nil {
-			log.Println("using optimal checker")
-			frequencyBlacklist := checkers.LoadFrequencyBlacklist(userService.config.Checker.Optimal.File, userService.config.MinPasswordLength)
-			ball := userService.checker.CheckOptimal(form.Password, frequencyBlacklist, userService.config.Checker.Optimal.QthMostProbablePassword)
-
-			// if password check fails, increment login attempts
-			if !checkPasswordAndBall(form.Password, ball, user.PasswordHash) {
-				// increment login attempts
-				err = userService.checkLoginAttempts(w, r, user)
-				if err != nil {
-					return form, errors.New("failed to check login attemps")
-				}
-			}
-		}
-
-		log.Println("successfully logged in")
-
-		// init session
-		_, err = session.SetUserID(w, r, strconv.Itoa(user.ID))
-		if err != nil {
-			return form, errors.New("could not create a session for user " + form.Email + ": " + err.Error())
+		// only use the correctors the user needs
+		if userService.policy != nil {
+			credential.Correctors = userService.policy.Correctors(userService.config.Correctors, user.Corrections, user.Logins)
 		}
-
 	} else {
-		log.Println("using typtop checker")
-
-		typtopUser, err := userService.getTypTopUser(form.Email)
+		typtopUser, err = userService.getTypTopUser(form.Email)
 		if err != nil {
 			log.Println("could not get typtop user " + form.Email + ": " + err.Error())
 			form.Errors["Login"] = "Username and password incorrect"
 			return form, errors.New("you must submit a valid form")
 		}
+		credential = &checkers.Credential{State: typtopUser.State, PrivateKey: typtopUser.PrivateKey}
+		registeredLayout = typtopUser.Layout
+	}
 
-		success, typtopState := userService.typtop.Login(typtopUser.State, form.Password, typtopUser.PrivateKey)
+	// correct typos using the keyboard layout of the request or of the user
+	checker := userService.checker.WithOptions(&correctors.Options{Layout: userService.layout(form.Layout, registeredLayout)})
 
-		if !success {
-			// increment login attempts
+	result, err := verifyPassword(r.Context(), checker, r.PostFormValue("password"), form.Password, credential)
+	if err != nil {
+		log.Println("could not verify the password of user " + form.Email + ": " + err.Error())
+		form.Errors["Login"] = "Username and password incorrect"
+		return form, errors.New("you must submit a valid form")
+	}
+
+	if typtopUser != nil {
+		// update user state
+		typtopUser.State = result.State
+		userService.recordResult(typtopUser.ID, result)
+	} else {
+		userService.recordResult(user.ID, result)
+	}
+
+	if !result.Match {
+		// increment login attempts
+		if typtopUser != nil {
 			err = userService.checkTypTopLoginAttempts(w, r, typtopUser)
-			if err != nil {
-				return form, errors.New("failed to check login attemps")
-			}
+		} else {
+			err = userService.checkLoginAttempts(w, r, user)
+		}
+		if err != nil {
+			return form, errors.New("failed to check login attemps")
 		}
+	}
 
-		// update user state
-		typtopUser.State = typtopState
+	if result.Corrector != "" {
+		log.Println("corrected the password of user " + form.Email + " with " + result.Corrector)
+	}
 
+	id := 0
+	if typtopUser != nil {
 		userService.updateTypTopUser(typtopUser)
+		id = typtopUser.ID
+	} else {
+		// only the exact password is rehashed, never a correction of a typo
+		if result.Kind == checkers.ExactMatch {
+			err = userService.rehashPassword(user, form.Password)
+			if err != nil {
+				log.Println("could not rehash the password of user " + user.Email + ": " + err.Error())
+			}
+		}
+		if userService.policy != nil {
+			userService.recordLogin(user, result)
+		}
+		id = user.ID
+	}
 
-		log.Println("successfully logged in")
+	log.Println("successfully logged in")
 
-		// init session
-		_, err = session.SetUserID(w, r, strconv.Itoa(typtopUser.ID))
-		if err != nil {
-			return form, errors.New("could not create a session for typtop user " + form.Email + ": " + err.Error())
-		}
+	// init session
+	_, err = session.SetUserID(w, r, strconv.Itoa(id))
+	if err != nil {
+		return form, errors.New("could not create a session for user " + form.Email + ": " + err.Error())
 	}
 
 	return form, nil
 }
 
-// Check the submitted password first, then remainder of the ball
-// this way timing attacks only tell if a corrector is used
-func checkPasswordAndBall(submittedPassword string, ball []string, registeredPasswordHash string) bool {
-	if CheckPasswordHash(submittedPassword, registeredPasswordHash) {
-		return true
-	}
+// recordLogin records the correctors that corrected the login and updates the correctors chosen for the user
+func (userService *UserService) recordLogin(user *User, result *checkers.Result) {
+	user.Logins++
+	user.Corrections = userService.policy.Record(user.Corrections, result)
+	user.Correctors = userService.policy.Correctors(userService.config.Correctors, user.Corrections, user.Logins)
 
-	success := false
-	for _, password := range ball {
-		if CheckPasswordHash(password, registeredPasswordHash) {
-			success = true
-		}
+	err := userService.updateUser(user)
+	if err != nil {
+		log.Println("could not record the login of user " + user.Email + ": " + err.Error())
 	}
-
-	return success
 }
 
 func (userService *UserService) checkLoginAttempts(w http.ResponseWriter, r *http.Request, user *User) error {
@@ -0,0 +1,41 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ppartarr/tipsy/checkers"
+	"github.com/ppartarr/tipsy/checkers/hashers"
+	"github.com/ppartarr/tipsy/correctors"
+	"github.com/stretchr/testify/assert"
+)
+
+// a user registered before the normalisation, with a password typed on macOS in NFD, can still login
+func TestVerifyPasswordNotNormalised(t *testing.T) {
+	submitted := "cafe\u0301!"
+	normalised, err := correctors.Normalise(submitted, correctors.NFC)
+	assert.Nil(t, err)
+	assert.NotEqual(t, submitted, normalised)
+
+	hash, err := hashers.NewBcrypt(4).Hash(submitted)
+	assert.Nil(t, err)
+	credential := &checkers.Credential{PasswordHash: hash}
+	checker := checkers.NewAlways(checkers.NewService(map[string]int{}, []string{correctors.SwitchAll}))
+
+	result, err := verifyPassword(context.Background(), checker, submitted, normalised, credential)
+	assert.Nil(t, err)
+	assert.True(t, result.Match)
+	assert.Equal(t, submitted, result.Password)
+
+	// a password registered after the normalisation matches the normalised password
+	hash, err = hashers.NewBcrypt(4).Hash(normalised)
+	assert.Nil(t, err)
+	result, err = verifyPassword(context.Background(), checker, submitted, normalised, &checkers.Credential{PasswordHash: hash})
+	assert.Nil(t, err)
+	assert.True(t, result.Match)
+	assert.Equal(t, normalised, result.Password)
+
+	result, err = verifyPassword(context.Background(), checker, "drowssap", "drowssap", credential)
+	assert.Nil(t, err)
+	assert.False(t, result.Match)
+}
@@ -0,0 +1,85 @@
+package users
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ppartarr/tipsy/checkers"
+	bolt "go.etcd.io/bbolt"
+)
+
+// LoginRecord is the outcome of a login, it's used to measure how often each corrector corrects a login
+type LoginRecord struct {
+	UserID    int                `json:"userId"`
+	Time      time.Time          `json:"time"`
+	Kind      checkers.MatchKind `json:"kind"`
+	Corrector string             `json:"corrector,omitempty"`
+	Chain     []string           `json:"chain,omitempty"`
+	BallSize  int                `json:"ballSize"`
+}
+
+// recordResult saves the result of the verification of the password of a user in the logins bucket
+func (userService *UserService) recordResult(userID int, result *checkers.Result) {
+	record := &LoginRecord{
+		UserID:    userID,
+		Time:      time.Now(),
+		Kind:      result.Kind,
+		Corrector: result.Corrector,
+		Chain:     result.Chain,
+		BallSize:  result.BallSize,
+	}
+
+	log.Println("login of user", userID, "kind:", record.Kind, "corrector:", record.Corrector, "ball size:", record.BallSize)
+
+	err := userService.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("logins"))
+		if bucket == nil {
+			return errors.New("bucket logins not found")
+		}
+
+		// records are keyed by their sequence so that they are kept in order
+		id, _ := bucket.NextSequence()
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, id)
+
+		buf, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, buf)
+	})
+	if err != nil {
+		log.Println("could not record the login of user", userID, ":", err.Error())
+	}
+}
+
+// LoginRecords returns the recorded logins in order
+func (userService *UserService) LoginRecords() ([]*LoginRecord, error) {
+	records := make([]*LoginRecord, 0)
+
+	err := userService.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("logins"))
+		if bucket == nil {
+			return errors.New("bucket logins not found")
+		}
+
+		return bucket.ForEach(func(key []byte, value []byte) error {
+			record := &LoginRecord{}
+			err := json.Unmarshal(value, record)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/nbutton23/zxcvbn-go"
 	"github.com/ppartarr/tipsy/checkers"
@@ -19,13 +18,14 @@ type RegistrationForm struct {
 	Email        string
 	Password     string
 	PasswordCopy string
+	Layout       string
 	Errors       map[string]string
 }
 
 var rxEmail = regexp.MustCompile(".+@.+\\..+")
 
 // Validate checks that the fields in the login form are set
-func (form *RegistrationForm) Validate(blacklistFile string, zxcvbnScore int) bool {
+func (form *RegistrationForm) Validate(blacklist checkers.PasswordSet, zxcvbnScore int, maxPasswordLength int) bool {
 	form.Errors = make(map[string]string)
 
 	// check that username is an email address
@@ -34,18 +34,30 @@ func (form *RegistrationForm) Validate(blacklistFile string, zxcvbnScore int) bo
 		form.Errors["Email"] = "Email must be valid"
 	}
 
-	if strings.TrimSpace(form.Password) == "" {
+	// whitespace is part of the password, only reject empty passwords
+	if form.Password == "" {
 		form.Errors["Password"] = "Password cannot be empty"
 	}
 
+	if len(form.Password) > maxPasswordLength {
+		form.Errors["Password"] = "Password cannot be longer than " + strconv.Itoa(maxPasswordLength) + " bytes"
+	}
+
 	// check that password & password copy match
 	if form.Password != form.PasswordCopy {
 		form.Errors["Password"] = "Passwords should match"
 	}
 
+	// check that the keyboard layout exists
+	if form.Layout != "" {
+		_, err := correctors.GetLayout(form.Layout)
+		if err != nil {
+			form.Errors["Layout"] = "Keyboard layout is unknown"
+		}
+	}
+
 	// check if password is in blacklist
-	blacklist := checkers.LoadBlacklist(blacklistFile)
-	if correctors.StringInSlice(form.Password, blacklist) {
+	if blacklist.Contains(form.Password) {
 		form.Errors["Password"] = "Password is forbidden"
 	}
 
@@ -73,12 +85,13 @@ func (userService *UserService) Register(w http.ResponseWriter, r *http.Request)
 	log.Println(r.Form)
 	form = &RegistrationForm{
 		Email:        r.PostFormValue("email"),
-		Password:     r.PostFormValue("password"),
-		PasswordCopy: r.PostFormValue("password-copy"),
+		Password:     userService.normalise(r.PostFormValue("password")),
+		PasswordCopy: userService.normalise(r.PostFormValue("password-copy")),
+		Layout:       r.PostFormValue("layout"),
 	}
 
 	// validate form
-	if form.Validate(userService.config.Web.Register.Blacklist, userService.config.Web.Register.Zxcvbn) == false {
+	if form.Validate(userService.registerBlacklist, userService.config.Web.Register.Zxcvbn, userService.config.MaxPasswordLength) == false {
 		log.Println(form.Errors)
 		return form, errors.New("you must submit a valid form")
 	}
@@ -96,10 +109,13 @@ func (userService *UserService) Register(w http.ResponseWriter, r *http.Request)
 
 		// init the checker service
 		log.Println("init checker service")
-		Checker := typtop.NewChecker(userService.config.Checker.TypTop, userService.config.Typos)
+		Checker := userService.typtop.WithLayout(userService.layout(form.Layout, ""))
 
 		// register the password for typtop
-		typtopState, privateKey := Checker.Register(form.Password)
+		typtopState, privateKey, err := Checker.Register(form.Password)
+		if err != nil {
+			return nil, errors.New("couldn't register the typtop user: " + err.Error())
+		}
 
 		// create new user from request then save in db
 		typtopUser = &typtop.User{
@@ -107,6 +123,7 @@ func (userService *UserService) Register(w http.ResponseWriter, r *http.Request)
 			LoginAttempts: 0,
 			State:         typtopState,
 			PrivateKey:    privateKey,
+			Layout:        form.Layout,
 		}
 
 		log.Println(typtopUser)
@@ -125,7 +142,7 @@ func (userService *UserService) Register(w http.ResponseWriter, r *http.Request)
 			return form, errors.New("you must submit a valid form")
 		}
 
-		passwordHash, err := HashPassword(form.Password)
+		passwordHash, err := userService.hasher.Hash(form.Password)
 		if err != nil {
 			return nil, errors.New("couldn't hash password: " + err.Error())
 		}
@@ -135,6 +152,7 @@ func (userService *UserService) Register(w http.ResponseWriter, r *http.Request)
 			Email:         form.Email,
 			PasswordHash:  passwordHash,
 			LoginAttempts: 0,
+			Layout:        form.Layout,
 		}
 
 		err = userService.createUser(user)
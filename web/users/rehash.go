@@ -0,0 +1,96 @@
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync/atomic"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// rehashPassword replaces the hash of the user with a hash of the current hasher, if the hash is weaker than the
+// hasher config. The password must be the exact password of the user, never a correction of the ball.
+// The ball is verified and the new hash computed outside of the transaction: bolt has a single writer, so hashing inside
+// db.Update would serialise every login behind the slowest hash. The transaction only writes the new hash if the stored
+// hash is still the one that was verified
+func (userService *UserService) rehashPassword(user *User, password string) error {
+	if !userService.hasher.NeedsRehash(user.PasswordHash) {
+		return nil
+	}
+
+	passwordHash, err := userService.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	err = userService.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("users"))
+		if bucket == nil {
+			return errors.New("bucket users not found")
+		}
+
+		stored := &User{}
+		err := json.Unmarshal(bucket.Get([]byte(user.Email)), stored)
+		if err != nil {
+			return err
+		}
+
+		// the password was reset since the login
+		if stored.PasswordHash != user.PasswordHash {
+			return errors.New("the password of user " + user.Email + " changed during the login")
+		}
+
+		stored.PasswordHash = passwordHash
+		buf, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(user.Email), buf)
+	})
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = passwordHash
+	legacy := atomic.AddInt64(&userService.legacyHashes, -1)
+	log.Println("rehashed the password of user "+user.Email+" with "+userService.hasher.Name()+",", legacy, "users remain on legacy password hashes")
+	return nil
+}
+
+// LegacyHashes returns the number of users whose password hash is weaker than the hasher config, they are rehashed
+// the next time they login with their exact password
+func (userService *UserService) LegacyHashes() int {
+	return int(atomic.LoadInt64(&userService.legacyHashes))
+}
+
+// countLegacyHashes counts the users whose password hash is weaker than the hasher config
+func (userService *UserService) countLegacyHashes() (int, error) {
+	legacy := 0
+
+	err := userService.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("users"))
+		if bucket == nil {
+			return errors.New("bucket users not found")
+		}
+
+		return bucket.ForEach(func(key []byte, value []byte) error {
+			user := &User{}
+			err := json.Unmarshal(value, user)
+			if err != nil {
+				return err
+			}
+			// typtop users have no password hash
+			if user.PasswordHash != "" && userService.hasher.NeedsRehash(user.PasswordHash) {
+				legacy++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return legacy, nil
+}
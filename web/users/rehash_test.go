@@ -0,0 +1,87 @@
+package users
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ppartarr/tipsy/checkers/hashers"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+// testUserService returns a user service on a temporary bolt database, whose hasher is stronger than bcrypt
+func testUserService(t *testing.T) (*UserService, func()) {
+	dir, err := ioutil.TempDir("", "users")
+	assert.Nil(t, err)
+
+	db, err := bolt.Open(filepath.Join(dir, "users.db"), 0600, nil)
+	assert.Nil(t, err)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte("users"))
+		return err
+	})
+	assert.Nil(t, err)
+
+	userService := &UserService{db: db, hasher: hashers.NewArgon2id(1024, 1, 1, 32)}
+	return userService, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRehashPassword(t *testing.T) {
+	userService, cleanup := testUserService(t)
+	defer cleanup()
+
+	legacyHash, err := hashers.NewBcrypt(4).Hash("password")
+	assert.Nil(t, err)
+	assert.Nil(t, userService.createUser(&User{Email: "user@tipsy.com", PasswordHash: legacyHash}))
+	userService.legacyHashes = 1
+
+	user, err := userService.getUser("user@tipsy.com")
+	assert.Nil(t, err)
+	assert.Nil(t, userService.rehashPassword(user, "password"))
+
+	stored, err := userService.getUser("user@tipsy.com")
+	assert.Nil(t, err)
+	assert.False(t, userService.hasher.NeedsRehash(stored.PasswordHash))
+	match, err := hashers.Verify("password", stored.PasswordHash)
+	assert.Nil(t, err)
+	assert.True(t, match)
+	assert.Equal(t, 0, userService.LegacyHashes())
+}
+
+// a password reset between the verification of the login and the rehash must not be overwritten by the rehash
+func TestRehashPasswordAfterReset(t *testing.T) {
+	userService, cleanup := testUserService(t)
+	defer cleanup()
+
+	legacyHash, err := hashers.NewBcrypt(4).Hash("password")
+	assert.Nil(t, err)
+	assert.Nil(t, userService.createUser(&User{Email: "user@tipsy.com", PasswordHash: legacyHash}))
+	userService.legacyHashes = 1
+
+	// the login verified the legacy hash
+	verified, err := userService.getUser("user@tipsy.com")
+	assert.Nil(t, err)
+
+	// the password is reset before the rehash
+	reset, err := userService.getUser("user@tipsy.com")
+	assert.Nil(t, err)
+	reset.PasswordHash, err = hashers.NewBcrypt(4).Hash("new password")
+	assert.Nil(t, err)
+	assert.Nil(t, userService.updateUser(reset))
+
+	assert.NotNil(t, userService.rehashPassword(verified, "password"))
+
+	stored, err := userService.getUser("user@tipsy.com")
+	assert.Nil(t, err)
+	assert.Equal(t, reset.PasswordHash, stored.PasswordHash, "the user should keep the reset password")
+	match, err := hashers.Verify("new password", stored.PasswordHash)
+	assert.Nil(t, err)
+	assert.True(t, match)
+	assert.Equal(t, 1, userService.LegacyHashes())
+}
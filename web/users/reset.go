@@ -5,7 +5,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/nbutton23/zxcvbn-go"
@@ -38,10 +38,15 @@ func (form *ResetForm) Validate(config *config.Server, zxcvbnScore int, token *R
 		form.Errors["Email"] = "the token expired at " + token.CreatedAt.Add(token.TTL).String()
 	}
 
-	if strings.TrimSpace(form.Password) == "" {
+	// whitespace is part of the password, only reject empty passwords
+	if form.Password == "" {
 		form.Errors["Password"] = "Password cannot be empty"
 	}
 
+	if len(form.Password) > config.MaxPasswordLength {
+		form.Errors["Password"] = "Password cannot be longer than " + strconv.Itoa(config.MaxPasswordLength) + " bytes"
+	}
+
 	// check that password & password copy match
 	if form.Password != form.PasswordCopy {
 		form.Errors["Password"] = "Passwords should match"
@@ -80,8 +85,8 @@ func (userService *UserService) PasswordReset(w http.ResponseWriter, r *http.Req
 	log.Println(r.Form)
 	form = &ResetForm{
 		Email:        r.PostFormValue("email"),
-		Password:     r.PostFormValue("password"),
-		PasswordCopy: r.PostFormValue("password-copy"),
+		Password:     userService.normalise(r.PostFormValue("password")),
+		PasswordCopy: userService.normalise(r.PostFormValue("password-copy")),
 		Token:        r.PostFormValue("token"),
 	}
 
@@ -103,11 +108,14 @@ func (userService *UserService) PasswordReset(w http.ResponseWriter, r *http.Req
 	log.Println(user.ResetToken.Token)
 
 	// hash the input password
-	passwordHash, err := HashPassword(r.Form["password"][0])
+	passwordHash, err := userService.hasher.Hash(form.Password)
 	if err != nil {
 		return nil, errors.New("failed to hash the input password: " + err.Error())
 	}
 
+	// the reset replaces a legacy hash like a rehash does
+	replacesLegacyHash := userService.hasher.NeedsRehash(user.PasswordHash) && !userService.hasher.NeedsRehash(passwordHash)
+
 	// delete token, set new password, set login attempts to 0
 	user.ResetToken = nil
 	user.PasswordHash = passwordHash
@@ -121,5 +129,10 @@ func (userService *UserService) PasswordReset(w http.ResponseWriter, r *http.Req
 
 	log.Println("password has been reset")
 
+	if replacesLegacyHash {
+		legacy := atomic.AddInt64(&userService.legacyHashes, -1)
+		log.Println(legacy, "users remain on legacy password hashes")
+	}
+
 	return nil, nil
 }
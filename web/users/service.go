@@ -7,18 +7,25 @@ import (
 	"time"
 
 	"github.com/ppartarr/tipsy/checkers"
+	"github.com/ppartarr/tipsy/checkers/hashers"
 	"github.com/ppartarr/tipsy/checkers/typtop"
 	"github.com/ppartarr/tipsy/config"
+	"github.com/ppartarr/tipsy/correctors"
 	bolt "go.etcd.io/bbolt"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // UserService represents the user service
 type UserService struct {
 	db      *bolt.DB
 	config  *config.Server
-	checker *checkers.Checker
+	checker checkers.Checker
 	typtop  *typtop.Checker
+	policy  *checkers.Policy
+	hasher  hashers.PasswordHasher
+	// registerBlacklist is the blacklist of passwords that can't be registered, loaded once
+	registerBlacklist checkers.PasswordSet
+	// legacyHashes is the number of users whose password hash is weaker than the hasher config
+	legacyHashes int64
 }
 
 type User struct {
@@ -27,6 +34,12 @@ type User struct {
 	PasswordHash  string      `json:"password"`
 	LoginAttempts int         `json:"loginAttempts"`
 	ResetToken    *ResetToken `json:"resetToken"`
+	Layout        string      `json:"layout,omitempty"`
+	// Logins is the number of successful logins, and Corrections the number of logins corrected by each corrector
+	Logins      int            `json:"logins,omitempty"`
+	Corrections map[string]int `json:"corrections,omitempty"`
+	// Correctors are the correctors chosen for the user when the correctors are personalised
+	Correctors []string `json:"correctors,omitempty"`
 }
 
 // ResetToken represents a password reset token
@@ -49,6 +62,11 @@ func NewUserService(db *bolt.DB, tipsyConfig *config.Server) (userService *UserS
 		if err != nil {
 			return errors.New("create bucket: " + err.Error())
 		}
+		// create logins bucket for the results of each login
+		_, err = tx.CreateBucketIfNotExists([]byte("logins"))
+		if err != nil {
+			return errors.New("create bucket: " + err.Error())
+		}
 		if tipsyConfig.Checker.TypTop != nil {
 			// create typtop users bucket
 			_, err = tx.CreateBucketIfNotExists([]byte("typtopUsers"))
@@ -59,24 +77,91 @@ func NewUserService(db *bolt.DB, tipsyConfig *config.Server) (userService *UserS
 		return nil
 	})
 
-	return &UserService{
-		db:      db,
-		config:  tipsyConfig,
-		checker: checkers.NewChecker(tipsyConfig.Typos, tipsyConfig.Correctors),
-		typtop:  typtop.NewChecker(tipsyConfig.Checker.TypTop, tipsyConfig.Typos),
+	checker, err := checkers.New(tipsyConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var policy *checkers.Policy
+	if tipsyConfig.Checker.Personalise != nil {
+		policy = checkers.NewPolicy(tipsyConfig.Checker.Personalise)
+	}
+
+	hasher, err := hashers.New(tipsyConfig.Hasher)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tipsyConfig.Hasher != nil {
+		hashers.SetLimits(tipsyConfig.Hasher.Limits)
+	}
+
+	registerBlacklist, err := checkers.LoadBlacklistFile(&config.BlacklistChecker{
+		File:   tipsyConfig.Web.Register.Blacklist,
+		Filter: config.SetFilter,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	userService = &UserService{
+		db:                db,
+		config:            tipsyConfig,
+		checker:           checker,
+		typtop:            typtop.NewChecker(tipsyConfig.Checker.TypTop, tipsyConfig.Typos).WithOptions(tipsyConfig.CorrectorOptions()),
+		policy:            policy,
+		hasher:            hasher,
+		registerBlacklist: registerBlacklist,
+	}
+
+	legacy, err := userService.countLegacyHashes()
+	if err != nil {
+		log.Println("could not count the legacy password hashes: " + err.Error())
+	} else {
+		userService.legacyHashes = int64(legacy)
+		log.Println(legacy, "users have a password hash weaker than the "+hasher.Name()+" hasher config")
 	}
+
+	return userService
 }
 
-// HashPassword hashes a password with bcrypt
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+// layout returns the keyboard layout requested at login, or else the user's, or else the default layout
+func (userService *UserService) layout(requested string, registered string) *correctors.Layout {
+	for _, name := range []string{requested, registered} {
+		if name == "" {
+			continue
+		}
+
+		layout, err := correctors.GetLayout(name)
+		if err == nil {
+			return layout
+		}
+		log.Println(err)
+	}
+
+	if userService.config.Keyboard != nil {
+		layout, err := correctors.GetLayout(userService.config.Keyboard.Layout)
+		if err == nil {
+			return layout
+		}
+		log.Println(err)
+	}
+
+	return correctors.USQwerty
 }
 
-// CheckPasswordHash verifies that the password arguments matches the given hash
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+// normalise applies the configured unicode normalisation so that passwords typed on different OSes are hashed the same way
+func (userService *UserService) normalise(password string) string {
+	if userService.config.Unicode == nil {
+		return password
+	}
+
+	normalised, err := correctors.Normalise(password, userService.config.Unicode.Normalisation)
+	if err != nil {
+		log.Println(err)
+		return password
+	}
+
+	return normalised
 }
 
 func (userService *UserService) incrementLoginAttempts(user *User) error {